@@ -0,0 +1,232 @@
+package config
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MatchRule names the rule that tied a Hyprland bind to an application
+type MatchRule string
+
+const (
+	MatchDesktopID  MatchRule = "desktop-id" // the bind launches the app's .desktop file
+	MatchCommand    MatchRule = "command"    // the bind runs the app's Exec line
+	MatchExecutable MatchRule = "executable" // the bind runs the app's executable, other arguments
+	MatchLabel      MatchRule = "label"      // the bind's description equals the app name
+)
+
+// MatchConfidence is how likely a match is to be right
+type MatchConfidence string
+
+const (
+	ConfidenceHigh   MatchConfidence = "high"
+	ConfidenceMedium MatchConfidence = "medium"
+	ConfidenceLow    MatchConfidence = "low"
+)
+
+// confidenceRank orders confidences, higher is better
+var confidenceRank = map[MatchConfidence]int{
+	ConfidenceLow:    1,
+	ConfidenceMedium: 2,
+	ConfidenceHigh:   3,
+}
+
+// BindMatch records how a keybinding read from the Hyprland config was matched to its app
+type BindMatch struct {
+	Rule       MatchRule
+	Confidence MatchConfidence
+	Value      string // what matched, e.g. the desktop file ID
+}
+
+// String describes the match, e.g. "executable firefox (medium)"
+func (m BindMatch) String() string {
+	if m.Value == "" {
+		return string(m.Rule) + " (" + string(m.Confidence) + ")"
+	}
+	return string(m.Rule) + " " + m.Value + " (" + string(m.Confidence) + ")"
+}
+
+// better reports whether m is more trustworthy than other
+func (m *BindMatch) better(other *BindMatch) bool {
+	return other == nil || confidenceRank[m.Confidence] > confidenceRank[other.Confidence]
+}
+
+// launcherWrappers are commands that run the command following them
+// The value is the separator ending the wrapper's own options ("" if it has none).
+var launcherWrappers = map[string]string{
+	"uwsm":     "--", // uwsm app -- firefox
+	"uwsm-app": "--",
+	"app2unit": "--",
+	"setsid":   "",
+	"env":      "",
+	"exec":     "",
+	"nohup":    "",
+}
+
+// desktopLaunchers start an application by desktop file ID or path
+var desktopLaunchers = map[string]bool{
+	"gtk-launch": true,
+	"dex":        true,
+	"kioclient":  true,
+}
+
+// bindCommand is the resolved command of an exec bind
+type bindCommand struct {
+	desktopID string   // desktop file ID when the bind launches a .desktop file
+	argv      []string // the command left after stripping launcher wrappers
+}
+
+// resolveBindCommand strips launcher wrappers (uwsm app --, setsid, env VAR=...) from an
+// exec bind's command, returning what actually gets started
+func resolveBindCommand(command string) bindCommand {
+	// "[workspace 2 silent] firefox": the rules don't change what is started
+	_, command = SplitExecRules(command)
+	// A focus-or-launch snippet starts its launch command
+	if launch, ok := SplitFocusOrLaunch(command); ok {
+		command = launch
+	}
+	args := splitCommand(command)
+	for len(args) > 0 {
+		name := filepath.Base(args[0])
+		separator, isWrapper := launcherWrappers[name]
+		switch {
+		case isWrapper:
+			args = skipWrapper(args[1:], separator)
+			continue
+		case desktopLaunchers[name]:
+			if id := desktopIDArg(args[1:]); id != "" {
+				return bindCommand{desktopID: id, argv: args}
+			}
+		case strings.HasSuffix(name, ".desktop"):
+			// uwsm app -- firefox.desktop
+			return bindCommand{desktopID: desktopID(args[0]), argv: args}
+		}
+		break
+	}
+	return bindCommand{argv: args}
+}
+
+// splitCommand splits an exec bind command or Exec line into arguments with SplitExec,
+// falling back to splitting on whitespace if its quotes don't balance
+func splitCommand(command string) []string {
+	if args, err := SplitExec(command); err == nil {
+		return args
+	}
+	return strings.Fields(command)
+}
+
+// skipWrapper drops a wrapper's own arguments: everything up to separator if it has one,
+// otherwise leading options and VAR=value assignments
+func skipWrapper(args []string, separator string) []string {
+	if separator != "" {
+		for i, arg := range args {
+			if arg == separator {
+				return args[i+1:]
+			}
+		}
+	}
+	for len(args) > 0 && (strings.HasPrefix(args[0], "-") || strings.Contains(args[0], "=") || args[0] == "app") {
+		args = args[1:]
+	}
+	return args
+}
+
+// desktopIDArg returns the desktop file ID given to a desktop launcher, skipping options
+func desktopIDArg(args []string) string {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || arg == "exec" {
+			continue
+		}
+		return desktopID(arg)
+	}
+	return ""
+}
+
+// desktopID returns the desktop file ID of a .desktop path or name
+func desktopID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".desktop")
+}
+
+// appExecutable returns the name of the executable app runs
+func appExecutable(app *Application) string {
+	if app.Exec != "" {
+		return extractExecutableName(app.Exec)
+	}
+	return app.PackageName
+}
+
+// sharedExecutables returns the executables run by more than one of apps, e.g.
+// omarchy-launch-webapp for every web app; a bind running one says nothing about
+// which app it launches
+func sharedExecutables(apps []Application) map[string]bool {
+	count := make(map[string]int)
+	for i := range apps {
+		if executable := appExecutable(&apps[i]); executable != "" {
+			count[executable]++
+		}
+	}
+	shared := make(map[string]bool)
+	for executable, n := range count {
+		if n > 1 {
+			shared[executable] = true
+		}
+	}
+	return shared
+}
+
+// matchBind returns the best rule tying bind to app, or nil if none applies
+// Commands are compared before the label; the label is only a fallback for binds
+// whose command says nothing about the app (e.g. a script). Executables in shared
+// are not matched on their own.
+func matchBind(app *Application, bind *hyprconf.Bind, command bindCommand, shared map[string]bool) *BindMatch {
+	if app.DesktopFile != "" && command.desktopID != "" && command.desktopID == desktopID(app.DesktopFile) {
+		return &BindMatch{Rule: MatchDesktopID, Confidence: ConfidenceHigh, Value: command.desktopID}
+	}
+
+	if len(command.argv) > 0 && command.desktopID == "" {
+		if app.Exec != "" && slices.Equal(stripFieldCodes(splitCommand(app.Exec)), command.argv) {
+			return &BindMatch{Rule: MatchCommand, Confidence: ConfidenceHigh, Value: strings.Join(command.argv, " ")}
+		}
+
+		executable := filepath.Base(command.argv[0])
+		if appExecutable := appExecutable(app); appExecutable != "" && executable == appExecutable && !shared[executable] {
+			return &BindMatch{Rule: MatchExecutable, Confidence: ConfidenceMedium, Value: executable}
+		}
+	}
+
+	if bind.HasDescription() && strings.EqualFold(strings.TrimSpace(bind.Description), app.Name) {
+		return &BindMatch{Rule: MatchLabel, Confidence: ConfidenceLow, Value: bind.Description}
+	}
+	return nil
+}
+
+// MatchesBind reports whether bind is the app's keybinding labelled label ("" for the
+// main binding), by the same rules the loader uses to attach binds to apps: the bind
+// must run the app (see matchBind) and its description, if any, names the binding
+func (a *Application) MatchesBind(bind *hyprconf.Bind, label string) bool {
+	if !bind.IsExec() || matchBind(a, bind, resolveBindCommand(bind.Args), nil) == nil {
+		return false
+	}
+	if label == "" {
+		label = a.Name
+	}
+	bindLabel := a.Name
+	if bind.HasDescription() && strings.TrimSpace(bind.Description) != "" {
+		bindLabel = strings.TrimSpace(bind.Description)
+	}
+	return strings.EqualFold(bindLabel, label)
+}
+
+// stripFieldCodes removes desktop entry field codes (%u, %F, ...) from the arguments of an Exec line
+func stripFieldCodes(args []string) []string {
+	var kept []string
+	for _, arg := range args {
+		if len(arg) == 2 && arg[0] == '%' {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
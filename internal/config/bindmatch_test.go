@@ -0,0 +1,111 @@
+package config
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveBindCommand(t *testing.T) {
+	tests := []struct {
+		command   string
+		desktopID string
+		argv      []string
+	}{
+		{"firefox", "", []string{"firefox"}},
+		{"uwsm app -- firefox --new-window", "", []string{"firefox", "--new-window"}},
+		{"uwsm-app -- nautilus --new-window", "", []string{"nautilus", "--new-window"}},
+		{"setsid env GDK_BACKEND=wayland uwsm app -- obsidian", "", []string{"obsidian"}},
+		{"[workspace 2 silent] uwsm app -- spotify", "", []string{"spotify"}},
+		{`omarchy-launch-webapp "https://chatgpt.com"`, "", []string{"omarchy-launch-webapp", "https://chatgpt.com"}},
+		{`uwsm app -- sh -c 'notify-send "hi there"'`, "", []string{"sh", "-c", `notify-send "hi there"`}},
+		{`app "/opt/My App/run"`, "", []string{"app", "/opt/My App/run"}},
+		// Unbalanced quotes fall back to splitting on whitespace
+		{`notify-send "oops`, "", []string{"notify-send", `"oops`}},
+		{"gtk-launch firefox", "firefox", []string{"gtk-launch", "firefox"}},
+		{"dex /usr/share/applications/org.gnome.Nautilus.desktop", "org.gnome.Nautilus", []string{"dex", "/usr/share/applications/org.gnome.Nautilus.desktop"}},
+		{"uwsm app -- firefox.desktop", "firefox", []string{"firefox.desktop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := resolveBindCommand(tt.command)
+			if got.desktopID != tt.desktopID || !reflect.DeepEqual(got.argv, tt.argv) {
+				t.Errorf("resolveBindCommand = %q, %q, want %q, %q", got.desktopID, got.argv, tt.desktopID, tt.argv)
+			}
+		})
+	}
+}
+
+func TestMatchBind(t *testing.T) {
+	firefox := Application{Name: "Firefox", PackageName: "firefox", Exec: "firefox %u", DesktopFile: "/usr/share/applications/firefox.desktop"}
+	chatGPT := Application{Name: "ChatGPT", PackageName: "omarchy-launch-webapp", Exec: `omarchy-launch-webapp "https://chatgpt.com"`}
+	basecamp := Application{Name: "Basecamp", PackageName: "omarchy-launch-webapp", Exec: `omarchy-launch-webapp "https://launchpad.37signals.com"`}
+	myApp := Application{Name: "My App", PackageName: "run", Exec: `"/opt/My App/run" --fast`}
+	shared := sharedExecutables([]Application{firefox, chatGPT, basecamp, myApp})
+
+	tests := []struct {
+		name string
+		app  Application
+		line string
+		want string // BindMatch.String(), "" for no match
+	}{
+		{"wrapped exec line", firefox, "bind = SUPER, B, exec, uwsm app -- firefox", "command firefox (high)"},
+		{"other arguments", firefox, "bind = SUPER, B, exec, uwsm app -- firefox --private-window", "executable firefox (medium)"},
+		{"desktop launcher", firefox, "bind = SUPER, B, exec, gtk-launch firefox", "desktop-id firefox (high)"},
+		{"desktop file", firefox, "bind = SUPER, B, exec, uwsm app -- firefox.desktop", "desktop-id firefox (high)"},
+		{"quoted argument", chatGPT, `bindd = SUPER SHIFT, A, ChatGPT, exec, omarchy-launch-webapp "https://chatgpt.com"`, "command omarchy-launch-webapp https://chatgpt.com (high)"},
+		{"single-quoted argument", chatGPT, `bind = SUPER SHIFT, A, exec, omarchy-launch-webapp 'https://chatgpt.com'`, "command omarchy-launch-webapp https://chatgpt.com (high)"},
+		{"shared executable", basecamp, `bindd = SUPER SHIFT, A, ChatGPT, exec, omarchy-launch-webapp "https://chatgpt.com"`, ""},
+		{"shared executable, label", basecamp, `bindd = SUPER SHIFT, B, Basecamp, exec, omarchy-launch-webapp "https://basecamp.com"`, "label Basecamp (low)"},
+		{"quoted path with a space", myApp, `bind = SUPER, M, exec, "/opt/My App/run" --fast`, "command /opt/My App/run --fast (high)"},
+		{"path split on whitespace", myApp, "bind = SUPER, M, exec, /opt/My App/run --fast", ""},
+		{"other app", firefox, "bind = SUPER, C, exec, chromium", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bind, err := hyprconf.ParseBindLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseBindLine: %v", err)
+			}
+			got := ""
+			if match := matchBind(&tt.app, bind, resolveBindCommand(bind.Args), shared); match != nil {
+				got = match.String()
+			}
+			if got != tt.want {
+				t.Errorf("matchBind = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractKeybindingsFromHyprSharedExecutable(t *testing.T) {
+	apps := []Application{
+		{Name: "Basecamp", PackageName: "omarchy-launch-webapp", Exec: `omarchy-launch-webapp "https://launchpad.37signals.com"`},
+		{Name: "ChatGPT", PackageName: "omarchy-launch-webapp", Exec: `omarchy-launch-webapp "https://chatgpt.com"`},
+		{Name: "HEY", PackageName: "omarchy-launch-webapp", Exec: `omarchy-launch-webapp "https://app.hey.com"`},
+	}
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	binds := `bindd = SUPER SHIFT, A, ChatGPT, exec, omarchy-launch-webapp "https://chatgpt.com"
+bindd = SUPER SHIFT, E, Mail, exec, omarchy-launch-webapp "https://app.hey.com/imbox"
+`
+	if err := os.WriteFile(path, []byte(binds), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hyprConfig, err := hyprconf.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	got := extractKeybindingsFromHypr(hyprConfig, apps)
+	if len(got[0]) != 0 {
+		t.Errorf("Basecamp got %v, want no keybindings", got[0])
+	}
+	if len(got[1]) != 1 || got[1][0].Keys.String() != "SUPER SHIFT, A" {
+		t.Errorf("ChatGPT got %v, want SUPER SHIFT, A", got[1])
+	}
+	if len(got[2]) != 0 {
+		t.Errorf("HEY got %v, want nothing for a different URL", got[2])
+	}
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DesktopEntry represents the [Desktop Entry] group of a .desktop file as
+// described by the freedesktop.org Desktop Entry Specification.
+// Localized keys (e.g. Name[de]) are resolved against the current locale.
+type DesktopEntry struct {
+	ID             string // desktop file ID, e.g. "org.gnome.Nautilus.desktop"
+	FilePath       string
+	Type           string
+	Version        string
+	Name           string
+	GenericName    string
+	Comment        string
+	Icon           string
+	TryExec        string
+	Exec           string
+	Path           string
+	Terminal       bool
+	NoDisplay      bool
+	Hidden         bool
+	OnlyShowIn     []string
+	NotShowIn      []string
+	Actions        []string
+	MimeType       []string
+	Categories     []string
+	Keywords       []string
+	StartupNotify  bool
+	StartupWMClass string
+	URL            string
+	Extra          map[string]string // keys not covered above (X-*, etc.)
+}
+
+// ParseDesktopEntry reads and parses the .desktop file at filePath
+func ParseDesktopEntry(filePath string) (*DesktopEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entry, err := parseDesktopEntry(file, currentLocale())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	entry.FilePath = filePath
+	entry.ID = filepath.Base(filePath)
+	return entry, nil
+}
+
+// parseDesktopEntry parses the [Desktop Entry] group from r, picking localized
+// values that best match locale (in LC_MESSAGES form, e.g. "de_DE.UTF-8@euro")
+func parseDesktopEntry(r io.Reader, locale string) (*DesktopEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	// key -> locale ("" for unlocalized) -> raw value
+	values := make(map[string]map[string]string)
+	var order []string
+
+	inDesktopEntry := false
+	seenDesktopEntry := false
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip comments and empty lines
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Group headers
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group := line[1 : len(line)-1]
+			inDesktopEntry = group == "Desktop Entry"
+			if inDesktopEntry {
+				if seenDesktopEntry {
+					return nil, fmt.Errorf("line %d: duplicate [Desktop Entry] group", lineNum)
+				}
+				seenDesktopEntry = true
+			}
+			continue
+		}
+
+		if !inDesktopEntry {
+			continue
+		}
+
+		// Parse Key[locale]=value, whitespace around '=' is allowed
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+
+		loc := ""
+		if open := strings.IndexByte(key, '['); open >= 0 && strings.HasSuffix(key, "]") {
+			loc = key[open+1 : len(key)-1]
+			key = key[:open]
+		}
+		if key == "" {
+			continue
+		}
+
+		if _, ok := values[key]; !ok {
+			values[key] = make(map[string]string)
+			order = append(order, key)
+		}
+		// First occurrence wins, duplicate keys are invalid per the spec
+		if _, dup := values[key][loc]; !dup {
+			values[key][loc] = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !seenDesktopEntry {
+		return nil, fmt.Errorf("missing [Desktop Entry] group")
+	}
+
+	candidates := localeCandidates(locale)
+	get := func(key string) (string, bool) {
+		locs, ok := values[key]
+		if !ok {
+			return "", false
+		}
+		for _, c := range candidates {
+			if v, ok := locs[c]; ok {
+				return v, true
+			}
+		}
+		v, ok := locs[""]
+		return v, ok
+	}
+	str := func(key string) string {
+		v, _ := get(key)
+		return unescapeDesktopString(v)
+	}
+	list := func(key string) []string {
+		v, _ := get(key)
+		return splitDesktopList(v)
+	}
+	boolean := func(key string) bool {
+		v, _ := get(key)
+		return strings.EqualFold(v, "true")
+	}
+
+	entry := &DesktopEntry{
+		Type:           str("Type"),
+		Version:        str("Version"),
+		Name:           str("Name"),
+		GenericName:    str("GenericName"),
+		Comment:        str("Comment"),
+		Icon:           str("Icon"),
+		TryExec:        str("TryExec"),
+		Exec:           str("Exec"),
+		Path:           str("Path"),
+		Terminal:       boolean("Terminal"),
+		NoDisplay:      boolean("NoDisplay"),
+		Hidden:         boolean("Hidden"),
+		OnlyShowIn:     list("OnlyShowIn"),
+		NotShowIn:      list("NotShowIn"),
+		Actions:        list("Actions"),
+		MimeType:       list("MimeType"),
+		Categories:     list("Categories"),
+		Keywords:       list("Keywords"),
+		StartupNotify:  boolean("StartupNotify"),
+		StartupWMClass: str("StartupWMClass"),
+		URL:            str("URL"),
+		Extra:          make(map[string]string),
+	}
+
+	for _, key := range order {
+		if desktopEntryKnownKeys[key] {
+			continue
+		}
+		entry.Extra[key] = str(key)
+	}
+
+	return entry, nil
+}
+
+// desktopEntryKnownKeys lists the keys that map to DesktopEntry fields
+var desktopEntryKnownKeys = map[string]bool{
+	"Type": true, "Version": true, "Name": true, "GenericName": true,
+	"Comment": true, "Icon": true, "TryExec": true, "Exec": true,
+	"Path": true, "Terminal": true, "NoDisplay": true, "Hidden": true,
+	"OnlyShowIn": true, "NotShowIn": true, "Actions": true, "MimeType": true,
+	"Categories": true, "Keywords": true, "StartupNotify": true,
+	"StartupWMClass": true, "URL": true,
+}
+
+// IsApplication reports whether the entry is of Type=Application
+// An empty Type is treated as Application for compatibility with sloppy files
+func (e *DesktopEntry) IsApplication() bool {
+	return e.Type == "" || e.Type == "Application"
+}
+
+// ShowIn reports whether the entry should be shown in any of the given desktops
+// (the colon-separated XDG_CURRENT_DESKTOP value), honoring OnlyShowIn/NotShowIn
+func (e *DesktopEntry) ShowIn(currentDesktop string) bool {
+	desktops := strings.Split(currentDesktop, ":")
+
+	for _, d := range desktops {
+		for _, not := range e.NotShowIn {
+			if d != "" && strings.EqualFold(d, not) {
+				return false
+			}
+		}
+	}
+
+	if len(e.OnlyShowIn) == 0 {
+		return true
+	}
+	for _, d := range desktops {
+		for _, only := range e.OnlyShowIn {
+			if d != "" && strings.EqualFold(d, only) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TryExecAvailable reports whether the TryExec binary (if any) is installed
+func (e *DesktopEntry) TryExecAvailable() bool {
+	if e.TryExec == "" {
+		return true
+	}
+	if filepath.IsAbs(e.TryExec) {
+		info, err := os.Stat(e.TryExec)
+		return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+	}
+	_, err := exec.LookPath(e.TryExec)
+	return err == nil
+}
+
+// unescapeDesktopString expands the \s, \n, \t, \r and \\ escape sequences
+// Unknown escapes are kept verbatim so Exec quoting can be handled later
+func unescapeDesktopString(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 >= len(value) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// SplitExec tokenizes a desktop entry Exec value into an argv following the
+// Desktop Entry quoting rules: arguments are separated by spaces and may be
+// enclosed in double quotes, inside which \", \`, \$ and \\ are escapes.
+// Like GLib, single quotes are honored as in sh, so shell command lines such as
+// exec binds split the same way.
+// The value must already have had the general string escapes (\s, \\, ...) applied.
+func SplitExec(execLine string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote byte
+
+	for i := 0; i < len(execLine); i++ {
+		c := execLine[i]
+
+		if quote == '\'' {
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+			continue
+		}
+		if quote == '"' {
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				if i+1 < len(execLine) && strings.IndexByte("\"`$\\", execLine[i+1]) >= 0 {
+					i++
+					current.WriteByte(execLine[i])
+				} else {
+					current.WriteByte(c)
+				}
+			default:
+				current.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case '"', '\'':
+			quote = c
+			inArg = true
+		case '\\':
+			// Not allowed unquoted by the spec, but be lenient like most launchers
+			if i+1 < len(execLine) {
+				i++
+				current.WriteByte(execLine[i])
+			}
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in Exec line: %s", execLine)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty Exec line")
+	}
+	return args, nil
+}
+
+// splitDesktopList splits a semicolon-separated list value, honoring "\;"
+// and the usual string escapes. Empty elements are dropped.
+func splitDesktopList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) && value[i+1] == ';' {
+			current.WriteByte(';')
+			i++
+			continue
+		}
+		if c == '\\' && i+1 < len(value) {
+			// Keep the escape for unescapeDesktopString
+			current.WriteByte(c)
+			current.WriteByte(value[i+1])
+			i++
+			continue
+		}
+		if c == ';' {
+			if item := strings.TrimSpace(unescapeDesktopString(current.String())); item != "" {
+				items = append(items, item)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	if item := strings.TrimSpace(unescapeDesktopString(current.String())); item != "" {
+		items = append(items, item)
+	}
+	return items
+}
+
+// currentLocale returns the locale used for LC_MESSAGES lookups
+func currentLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// localeCandidates returns the locale keys to try, most specific first, as
+// defined by the spec: lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang
+func localeCandidates(locale string) []string {
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	// Drop the encoding part (e.g. ".UTF-8") while keeping the modifier
+	modifier := ""
+	if at := strings.IndexByte(locale, '@'); at >= 0 {
+		modifier = locale[at+1:]
+		locale = locale[:at]
+	}
+	if dot := strings.IndexByte(locale, '.'); dot >= 0 {
+		locale = locale[:dot]
+	}
+
+	lang, country := locale, ""
+	if us := strings.IndexByte(locale, '_'); us >= 0 {
+		lang, country = locale[:us], locale[us+1:]
+	}
+
+	var candidates []string
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	candidates = append(candidates, lang)
+	return candidates
+}
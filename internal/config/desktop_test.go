@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDesktopEntryLocalizedKeys(t *testing.T) {
+	const file = `[Desktop Entry]
+Type=Application
+Name=Files
+Name[de]=Dateien
+Name[de_DE]=Dateien (DE)
+Name[sr@latin]=Datoteke
+Comment=Browse files
+Comment[fr]=Parcourir
+Exec=nautilus --new-window %U
+`
+	tests := []struct {
+		locale  string
+		name    string
+		comment string
+	}{
+		{"", "Files", "Browse files"},
+		{"C", "Files", "Browse files"},
+		{"de", "Dateien", "Browse files"},
+		{"de_AT.UTF-8", "Dateien", "Browse files"},
+		{"de_DE.UTF-8", "Dateien (DE)", "Browse files"},
+		{"de_DE.UTF-8@euro", "Dateien (DE)", "Browse files"},
+		{"sr_RS@latin", "Datoteke", "Browse files"},
+		{"fr_FR", "Files", "Parcourir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			entry, err := parseDesktopEntry(strings.NewReader(file), tt.locale)
+			if err != nil {
+				t.Fatalf("parseDesktopEntry: %v", err)
+			}
+			if entry.Name != tt.name {
+				t.Errorf("Name = %q, want %q", entry.Name, tt.name)
+			}
+			if entry.Comment != tt.comment {
+				t.Errorf("Comment = %q, want %q", entry.Comment, tt.comment)
+			}
+		})
+	}
+}
+
+func TestParseDesktopEntryFields(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want func(*DesktopEntry) bool
+	}{
+		{
+			name: "hidden",
+			file: "[Desktop Entry]\nName=A\nExec=a\nHidden=true\n",
+			want: func(e *DesktopEntry) bool { return e.Hidden && !e.NoDisplay },
+		},
+		{
+			name: "no display",
+			file: "[Desktop Entry]\nName=A\nExec=a\nNoDisplay=True\n",
+			want: func(e *DesktopEntry) bool { return e.NoDisplay && !e.Hidden },
+		},
+		{
+			name: "booleans default to false",
+			file: "[Desktop Entry]\nName=A\nExec=a\nHidden=1\n",
+			want: func(e *DesktopEntry) bool { return !e.Hidden && !e.NoDisplay && !e.Terminal },
+		},
+		{
+			name: "link type",
+			file: "[Desktop Entry]\nType=Link\nName=Site\nURL=https://example.org\n",
+			want: func(e *DesktopEntry) bool { return !e.IsApplication() && e.URL == "https://example.org" },
+		},
+		{
+			name: "directory type",
+			file: "[Desktop Entry]\nType=Directory\nName=Games\n",
+			want: func(e *DesktopEntry) bool { return !e.IsApplication() },
+		},
+		{
+			name: "missing type is an application",
+			file: "[Desktop Entry]\nName=A\nExec=a\n",
+			want: func(e *DesktopEntry) bool { return e.IsApplication() },
+		},
+		{
+			name: "other groups are ignored",
+			file: "[Desktop Entry]\nName=A\nExec=a\n\n[Desktop Action new]\nName=New\nExec=a --new\n",
+			want: func(e *DesktopEntry) bool { return e.Name == "A" && e.Exec == "a" },
+		},
+		{
+			name: "first duplicate key wins",
+			file: "[Desktop Entry]\nName=A\nName=B\nExec=a\n",
+			want: func(e *DesktopEntry) bool { return e.Name == "A" },
+		},
+		{
+			name: "lists and escapes",
+			file: "[Desktop Entry]\nName=A\\sB\nExec=a\nCategories=Utility;Text\\;Editor;;\nX-Custom = yes\n",
+			want: func(e *DesktopEntry) bool {
+				return e.Name == "A B" &&
+					reflect.DeepEqual(e.Categories, []string{"Utility", "Text;Editor"}) &&
+					e.Extra["X-Custom"] == "yes"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := parseDesktopEntry(strings.NewReader(tt.file), "")
+			if err != nil {
+				t.Fatalf("parseDesktopEntry: %v", err)
+			}
+			if !tt.want(entry) {
+				t.Errorf("unexpected entry: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestParseDesktopEntryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"no desktop entry group", "[Desktop Action new]\nName=New\n"},
+		{"duplicate desktop entry group", "[Desktop Entry]\nName=A\n[Desktop Entry]\nName=B\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseDesktopEntry(strings.NewReader(tt.file), ""); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseDesktopFileFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		shown bool
+	}{
+		{"application", "[Desktop Entry]\nType=Application\nName=A\nExec=a %U\n", true},
+		{"hidden", "[Desktop Entry]\nType=Application\nName=A\nExec=a\nHidden=true\n", false},
+		{"no display", "[Desktop Entry]\nType=Application\nName=A\nExec=a\nNoDisplay=true\n", false},
+		{"link", "[Desktop Entry]\nType=Link\nName=A\nURL=https://example.org\n", false},
+		{"directory", "[Desktop Entry]\nType=Directory\nName=A\n", false},
+		{"only shown elsewhere", "[Desktop Entry]\nName=A\nExec=a\nOnlyShowIn=KDE;\n", false},
+		{"not shown here", "[Desktop Entry]\nName=A\nExec=a\nNotShowIn=Hyprland;\n", false},
+	}
+
+	t.Setenv("XDG_CURRENT_DESKTOP", "Hyprland")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "a.desktop")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			app, _, err := parseDesktopFile(path)
+			if shown := err == nil && app != nil; shown != tt.shown {
+				t.Errorf("shown = %v, want %v (err %v)", shown, tt.shown, err)
+			}
+		})
+	}
+}
+
+func TestSplitExec(t *testing.T) {
+	tests := []struct {
+		name    string
+		exec    string
+		want    []string
+		wantErr bool
+	}{
+		{"plain", "firefox %u", []string{"firefox", "%u"}, false},
+		{"extra spaces", "  code   --new-window\t%F ", []string{"code", "--new-window", "%F"}, false},
+		{"quoted space", `"/opt/My App/app" --flag`, []string{"/opt/My App/app", "--flag"}, false},
+		{"quote inside word", `sh -c "echo hi"`, []string{"sh", "-c", "echo hi"}, false},
+		{"adjacent quoted parts", `--title="a b"c`, []string{"--title=a bc"}, false},
+		{"escaped quote", `sh -c "echo \"hi\""`, []string{"sh", "-c", `echo "hi"`}, false},
+		{"escaped dollar and backtick", `sh -c "echo \$HOME \` + "`" + `"`, []string{"sh", "-c", "echo $HOME `"}, false},
+		{"escaped backslash", `app "a\\b"`, []string{"app", `a\b`}, false},
+		{"other backslash kept in quotes", `app "a\nb"`, []string{"app", `a\nb`}, false},
+		{"unquoted backslash", `app a\ b`, []string{"app", "a b"}, false},
+		{"empty quoted argument", `app ""`, []string{"app", ""}, false},
+		{"single quotes", `sh -c 'echo "hi" $HOME'`, []string{"sh", "-c", `echo "hi" $HOME`}, false},
+		{"single quote ends a double-quoted part", `app 'a b'"c d"`, []string{"app", "a bc d"}, false},
+		{"backslash kept in single quotes", `app 'a\b'`, []string{"app", `a\b`}, false},
+		{"unterminated quote", `app "oops`, nil, true},
+		{"unterminated single quote", `app 'oops`, nil, true},
+		{"empty", "   ", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitExec(tt.exec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitExec(%q) error = %v, wantErr %v", tt.exec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitExec(%q) = %q, want %q", tt.exec, got, tt.want)
+			}
+		})
+	}
+}
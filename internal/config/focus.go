@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// focusOrLaunchPrefix starts every command written by FocusOrLaunchCommand
+const focusOrLaunchPrefix = "window=$(hyprctl clients -j | jq -r "
+
+// FocusOrLaunchCommand wraps command in a shell snippet that focuses an open window of
+// class (compared case-insensitively with class and initialClass) or with initialTitle
+// title, and runs command only when there is none. It's what a single-instance app's
+// exec bind runs, so the hotkey behaves like launching from the TUI.
+// Example: window=$(hyprctl clients -j | jq -r '...'); if [ -n "${window}" ]; then
+// hyprctl dispatch focuswindow "address:${window}"; else firefox; fi
+// The shell variable is always written as ${window}: Hyprland substitutes its own
+// $variables in bind lines, and a "$win" variable would otherwise eat "$window".
+func FocusOrLaunchCommand(class, title, command string) string {
+	var conditions []string
+	if class != "" {
+		quoted := strconv.Quote(strings.ToLower(class))
+		conditions = append(conditions,
+			"(.class | ascii_downcase) == "+quoted,
+			"(.initialClass | ascii_downcase) == "+quoted)
+	}
+	if title != "" {
+		conditions = append(conditions, ".initialTitle == "+strconv.Quote(title))
+	}
+	if len(conditions) == 0 {
+		return command
+	}
+
+	// The most recently focused window first, like FindWindow
+	filter := fmt.Sprintf("[.[] | select(%s)] | sort_by(.focusHistoryID) | first | .address // empty",
+		strings.Join(conditions, " or "))
+	return fmt.Sprintf(`%s%s); if [ -n "${window}" ]; then hyprctl dispatch focuswindow "address:${window}"; else %s; fi`,
+		focusOrLaunchPrefix, singleQuote(filter), command)
+}
+
+// SplitFocusOrLaunch returns the launch command of a FocusOrLaunchCommand snippet
+// ok is false when command isn't one.
+func SplitFocusOrLaunch(command string) (launch string, ok bool) {
+	command = strings.TrimSpace(command)
+	if !strings.HasPrefix(command, focusOrLaunchPrefix) || !strings.HasSuffix(command, "; fi") {
+		return "", false
+	}
+	i := strings.LastIndex(command, "; else ")
+	if i < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(command[i+len("; else ") : len(command)-len("; fi")]), true
+}
+
+// singleQuote quotes s for the shell
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
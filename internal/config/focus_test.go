@@ -0,0 +1,43 @@
+package config
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"strings"
+	"testing"
+)
+
+func TestFocusOrLaunchCommand(t *testing.T) {
+	command := FocusOrLaunchCommand("Firefox", "", "uwsm app -- firefox")
+
+	if strings.Contains(strings.ReplaceAll(command, "${window}", ""), "$window") {
+		t.Errorf("command uses $window, which Hyprland variables can rewrite: %s", command)
+	}
+	if !strings.Contains(command, `(.class | ascii_downcase) == "firefox"`) {
+		t.Errorf("command doesn't match the class case-insensitively: %s", command)
+	}
+	if launch, ok := SplitFocusOrLaunch(command); !ok || launch != "uwsm app -- firefox" {
+		t.Errorf("SplitFocusOrLaunch = %q, %v", launch, ok)
+	}
+	if got := FocusOrLaunchCommand("", "", "firefox"); got != "firefox" {
+		t.Errorf("without class or title = %q, want the command unchanged", got)
+	}
+}
+
+func TestFocusOrLaunchCommandSurvivesHyprlandVariables(t *testing.T) {
+	command := FocusOrLaunchCommand("firefox", "", "firefox")
+	hyprConfig := &hyprconf.Config{Variables: map[string]string{"win": "SUPER", "window": "oops"}}
+	if got := hyprConfig.Substitute(command); got != command {
+		t.Errorf("Hyprland variables changed the command:\n%s\nbecame\n%s", command, got)
+	}
+}
+
+func TestSplitFocusOrLaunchEarlierSnippet(t *testing.T) {
+	// Binds written before the variable was braced
+	command := `window=$(hyprctl clients -j | jq -r '[.[] | select(.initialTitle == "Notes")] | sort_by(.focusHistoryID) | first | .address // empty'); if [ -n "$window" ]; then hyprctl dispatch focuswindow "address:$window"; else obsidian; fi`
+	if launch, ok := SplitFocusOrLaunch(command); !ok || launch != "obsidian" {
+		t.Errorf("SplitFocusOrLaunch = %q, %v", launch, ok)
+	}
+	if _, ok := SplitFocusOrLaunch("firefox"); ok {
+		t.Error("plain command taken for a focus-or-launch snippet")
+	}
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"omarchy-tui/internal/hyprconf"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Modifier is a bitset of keyboard modifiers
+type Modifier uint8
+
+const (
+	ModShift Modifier = 1 << iota
+	ModCtrl
+	ModAlt
+	ModSuper
+)
+
+// modifierOrder is the order modifiers are rendered in
+var modifierOrder = []Modifier{ModSuper, ModCtrl, ModAlt, ModShift}
+
+// hyprModifierNames are the canonical Hyprland spellings
+var hyprModifierNames = map[Modifier]string{
+	ModShift: "SHIFT",
+	ModCtrl:  "CTRL",
+	ModAlt:   "ALT",
+	ModSuper: "SUPER",
+}
+
+// displayModifierNames are the spellings used for the plus and spaced notations
+var displayModifierNames = map[Modifier]string{
+	ModShift: "Shift",
+	ModCtrl:  "Ctrl",
+	ModAlt:   "Alt",
+	ModSuper: "Super",
+}
+
+// KeybindingFormat is the notation a keybinding was written in
+type KeybindingFormat int
+
+const (
+	KeybindingFormatHyprland KeybindingFormat = iota // "SUPER SHIFT, A"
+	KeybindingFormatPlus                             // "Ctrl+Shift+V"
+	KeybindingFormatSpaced                           // "Ctrl Shift Alt V"
+)
+
+// Keybinding is a key combination
+// Values that can't be parsed (e.g. "default") are kept verbatim in Raw with an empty Key.
+type Keybinding struct {
+	Mods   Modifier
+	Key    string
+	Format KeybindingFormat // notation of the source text
+	Raw    string           // text as written, "" when built programmatically
+}
+
+// ParseKeybinding parses a keybinding in Hyprland ("SUPER SHIFT, A"),
+// plus ("Ctrl+Shift+V") or spaced ("Ctrl Shift Alt V") notation
+// On error the returned Keybinding still carries the text in Raw.
+func ParseKeybinding(s string) (Keybinding, error) {
+	s = strings.TrimSpace(s)
+	kb := Keybinding{Raw: s}
+	if s == "" {
+		return kb, nil
+	}
+
+	var modTokens []string
+	var key string
+	switch {
+	case strings.Contains(s, ","):
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 {
+			return kb, fmt.Errorf("invalid keybinding, expected 'MODIFIERS, KEY': %s", s)
+		}
+		kb.Format = KeybindingFormatHyprland
+		modTokens = strings.FieldsFunc(parts[0], func(r rune) bool { return r == ' ' || r == '\t' || r == '_' || r == '+' })
+		key = strings.TrimSpace(parts[1])
+	case strings.Contains(s, "+") && len(s) > 1:
+		parts := strings.Split(s, "+")
+		kb.Format = KeybindingFormatPlus
+		modTokens = parts[:len(parts)-1]
+		key = strings.TrimSpace(parts[len(parts)-1])
+	default:
+		fields := strings.Fields(s)
+		kb.Format = KeybindingFormatSpaced
+		modTokens = fields[:len(fields)-1]
+		key = fields[len(fields)-1]
+		if len(modTokens) == 0 && !hyprconf.IsKeyName(key) {
+			return kb, fmt.Errorf("not a keybinding: %s", s)
+		}
+	}
+
+	for _, token := range modTokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		mod, ok := parseModifier(token)
+		if !ok {
+			return kb, fmt.Errorf("unknown modifier '%s' in keybinding: %s", token, s)
+		}
+		kb.Mods |= mod
+	}
+
+	if key == "" {
+		return kb, fmt.Errorf("missing key in keybinding: %s", s)
+	}
+	if _, isMod := hyprconf.CanonicalModifier(key); isMod {
+		return kb, fmt.Errorf("missing key in keybinding: %s", s)
+	}
+	kb.Key = hyprconf.CanonicalKey(key)
+	return kb, nil
+}
+
+// parseModifier returns the modifier bit of a modifier spelling (see hyprconf.CanonicalModifier)
+func parseModifier(token string) (Modifier, bool) {
+	name, ok := hyprconf.CanonicalModifier(token)
+	if !ok {
+		return 0, false
+	}
+	for mod, hyprName := range hyprModifierNames {
+		if hyprName == name {
+			return mod, true
+		}
+	}
+	return 0, false
+}
+
+// IsZero reports whether no keybinding is set
+func (k Keybinding) IsZero() bool {
+	return k.Key == "" && k.Raw == ""
+}
+
+// IsValid reports whether the keybinding was parsed into modifiers and a key
+func (k Keybinding) IsValid() bool {
+	return k.Key != ""
+}
+
+// Has reports whether mod is part of the combination
+func (k Keybinding) Has(mod Modifier) bool {
+	return k.Mods&mod != 0
+}
+
+// Equal reports whether both keybindings are the same key combination
+func (k Keybinding) Equal(other Keybinding) bool {
+	if !k.IsValid() || !other.IsValid() {
+		return k.Raw == other.Raw && k.Key == other.Key
+	}
+	return k.Combo() == other.Combo()
+}
+
+// Combo returns the normalized key combination (see hyprconf.Combo)
+func (k Keybinding) Combo() string {
+	return hyprconf.Combo(k.HyprMods(), k.Key)
+}
+
+// Canonical returns the keybinding in Hyprland notation, dropping the source text
+func (k Keybinding) Canonical() Keybinding {
+	if !k.IsValid() {
+		return k
+	}
+	return Keybinding{Mods: k.Mods, Key: k.Key, Format: KeybindingFormatHyprland}
+}
+
+// HyprMods renders the modifiers as Hyprland expects them, e.g. "SUPER SHIFT"
+func (k Keybinding) HyprMods() string {
+	return k.joinMods(hyprModifierNames, " ")
+}
+
+// joinMods renders the set modifiers with names, in modifierOrder
+func (k Keybinding) joinMods(names map[Modifier]string, sep string) string {
+	var mods []string
+	for _, mod := range modifierOrder {
+		if k.Has(mod) {
+			mods = append(mods, names[mod])
+		}
+	}
+	return strings.Join(mods, sep)
+}
+
+// String renders the canonical Hyprland form, e.g. "SUPER SHIFT, A"
+// Unparsed keybindings render their raw text.
+func (k Keybinding) String() string {
+	if !k.IsValid() {
+		return k.Raw
+	}
+	return fmt.Sprintf("%s, %s", k.HyprMods(), k.Key)
+}
+
+// render renders the keybinding in its source notation
+func (k Keybinding) render() string {
+	if !k.IsValid() {
+		return k.Raw
+	}
+	switch k.Format {
+	case KeybindingFormatPlus:
+		if mods := k.joinMods(displayModifierNames, "+"); mods != "" {
+			return mods + "+" + k.Key
+		}
+		return k.Key
+	case KeybindingFormatSpaced:
+		if mods := k.joinMods(displayModifierNames, " "); mods != "" {
+			return mods + " " + k.Key
+		}
+		return k.Key
+	default:
+		return k.String()
+	}
+}
+
+// MarshalYAML writes the keybinding as a string, keeping the text as written
+// when it still describes the same combination
+func (k Keybinding) MarshalYAML() (interface{}, error) {
+	if k.Raw != "" {
+		parsed, err := ParseKeybinding(k.Raw)
+		if (err != nil && !k.IsValid()) || (err == nil && parsed.Equal(k)) {
+			return k.Raw, nil
+		}
+	}
+	return k.render(), nil
+}
+
+// UnmarshalYAML reads a keybinding string; unparseable values are kept in Raw
+func (k *Keybinding) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	kb, _ := ParseKeybinding(s)
+	*k = kb
+	return nil
+}
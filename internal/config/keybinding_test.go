@@ -0,0 +1,50 @@
+package config
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"testing"
+)
+
+func TestKeybindingComboMatchesBinds(t *testing.T) {
+	tests := []struct {
+		keybinding string
+		bind       string
+		same       bool
+	}{
+		{"SUPER, Return", "bind = SUPER, RETURN, exec, alacritty", true},
+		{"Super+Enter", "bind = SUPER, Return, exec, alacritty", true},
+		{"Ctrl Shift Esc", "bind = CONTROL SHIFT, escape, exec, btop", true},
+		{"Win+Alt+b", "bindd = MOD4 MOD1, B, Browser, exec, firefox", true},
+		{"SUPER, Del", "bind = SUPER, DELETE, killactive", true},
+		{"SUPER, B", "bind = SUPER SHIFT, B, exec, firefox", false},
+		{"SUPER, B", "bind = SUPER CAPS, B, exec, firefox", false},
+		{"SUPER, Return", "bind = SUPER, KP_Enter, exec, alacritty", false},
+	}
+	for _, tt := range tests {
+		kb, err := ParseKeybinding(tt.keybinding)
+		if err != nil {
+			t.Fatalf("ParseKeybinding(%q): %v", tt.keybinding, err)
+		}
+		bind, err := hyprconf.ParseBindLine(tt.bind)
+		if err != nil {
+			t.Fatalf("ParseBindLine(%q): %v", tt.bind, err)
+		}
+		if same := kb.Combo() == bind.Combo(); same != tt.same {
+			t.Errorf("%q vs %q: same combination = %v, want %v", tt.keybinding, tt.bind, same, tt.same)
+		}
+	}
+}
+
+func TestKeybindingEqual(t *testing.T) {
+	a := mustParseKeybinding(t, "SUPER SHIFT, enter")
+	b := mustParseKeybinding(t, "Shift+Super+Return")
+	if !a.Equal(b) {
+		t.Errorf("%v and %v should be equal", a, b)
+	}
+	if a.Key != "Return" || b.Key != "Return" {
+		t.Errorf("keys not canonical: %q, %q", a.Key, b.Key)
+	}
+	if c := mustParseKeybinding(t, "SUPER, Return"); a.Equal(c) {
+		t.Errorf("%v and %v should differ", a, c)
+	}
+}
@@ -1,8 +1,9 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
+	"omarchy-tui/internal/fsutil"
+	"omarchy-tui/internal/hyprconf"
 	"omarchy-tui/internal/logger"
 	"os"
 	"os/user"
@@ -12,10 +13,13 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// configFilePath is the location of the omarchy-tui configuration file
+const configFilePath = "~/.config/omarchy.conf.yaml"
+
 // LoadConfig loads and parses the YAML configuration file from ~/.config/omarchy.conf.yaml
 // If the config file is empty or missing, it auto-populates from .desktop files
 func LoadConfig() (*OmarchyConfig, error) {
-	configPath, err := expandPath("~/.config/omarchy.conf.yaml")
+	configPath, err := hyprconf.ExpandPath(configFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand config path: %w", err)
 	}
@@ -43,17 +47,18 @@ func LoadConfig() (*OmarchyConfig, error) {
 			AppsInventory: apps,
 		}
 
+		// Write the generated config before adding the keybindings found in the
+		// Hyprland config, which are derived on every load and never saved
+		if err := writeConfig(configPath, config); err != nil {
+			return nil, fmt.Errorf("failed to write config file: %w", err)
+		}
+
 		// Update keybindings from Hyprland config if available
 		if err := updateKeybindingsFromHypr(config); err != nil {
 			// Log but don't fail - keybindings are optional
 			// Could add logging here if logger is available
 		}
 
-		// Write the generated config
-		if err := writeConfig(configPath, config); err != nil {
-			return nil, fmt.Errorf("failed to write config file: %w", err)
-		}
-
 		return config, nil
 	}
 
@@ -72,6 +77,14 @@ func LoadConfig() (*OmarchyConfig, error) {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	// Fill in launch details for apps saved before they were tracked
+	fillDesktopMetadata(&config)
+
+	// Move single keybindings from older configs into the keybindings list
+	for i := range config.AppsInventory {
+		config.AppsInventory[i].migrateKeybinding()
+	}
+
 	// Update keybindings from Hyprland config if available
 	if err := updateKeybindingsFromHypr(&config); err != nil {
 		// Log but don't fail - keybindings are optional
@@ -81,24 +94,6 @@ func LoadConfig() (*OmarchyConfig, error) {
 	return &config, nil
 }
 
-// expandPath expands ~ to the user's home directory
-func expandPath(path string) (string, error) {
-	if len(path) == 0 || path[0] != '~' {
-		return path, nil
-	}
-
-	usr, err := user.Current()
-	if err != nil {
-		return "", err
-	}
-
-	if path == "~" {
-		return usr.HomeDir, nil
-	}
-
-	return filepath.Join(usr.HomeDir, path[1:]), nil
-}
-
 // validateConfig performs basic validation on the configuration
 func validateConfig(config *OmarchyConfig) error {
 	// Check for empty categories
@@ -142,6 +137,15 @@ func validateConfig(config *OmarchyConfig) error {
 		}
 	}
 
+	// Drop defaults of categories that no longer exist: a stale entry shouldn't
+	// keep the TUI from starting
+	for categoryID, def := range config.Defaults {
+		if !categoryIDs[categoryID] {
+			logger.Log("validateConfig: Ignoring default app '%s' of unknown category: %s", def.Name, categoryID)
+			delete(config.Defaults, categoryID)
+		}
+	}
+
 	return nil
 }
 
@@ -268,128 +272,120 @@ func scanDesktopFiles(homeDir string) ([]Application, []Category, error) {
 	return apps, categories, nil
 }
 
-// parseDesktopFile parses a .desktop file and returns an Application and the raw Categories string
-// Returns nil if the entry should not be displayed (NoDisplay=true, Hidden=true, or Type!=Application)
-func parseDesktopFile(filePath string) (*Application, string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, "", err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	inDesktopEntry := false
-	app := &Application{
-		CustomConfig: make(map[string]string),
-	}
-
-	var name, exec, categories, icon, entryType string
-	var noDisplay, hidden bool
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
+// fillDesktopMetadata fills empty Exec/DesktopFile/Terminal fields from the app's .desktop file:
+// the desktop_file it names, or <package_name>.desktop in the XDG data directories
+// Only those files are read, so apps that never had one (custom apps) don't cost a
+// directory scan on every load.
+func fillDesktopMetadata(config *OmarchyConfig) {
+	var dirs []string
+	for i := range config.AppsInventory {
+		app := &config.AppsInventory[i]
+		if app.Exec != "" {
 			continue
 		}
 
-		// Check for [Desktop Entry] section
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			if line == "[Desktop Entry]" {
-				inDesktopEntry = true
-			} else {
-				inDesktopEntry = false
+		var candidates []string
+		if app.DesktopFile != "" {
+			if path, err := hyprconf.ExpandPath(app.DesktopFile); err == nil {
+				candidates = append(candidates, path)
+			}
+		} else if app.PackageName != "" {
+			if dirs == nil {
+				usr, err := user.Current()
+				if err != nil {
+					return
+				}
+				dirs = getXDGDataDirs(usr.HomeDir)
+			}
+			for _, dir := range dirs {
+				candidates = append(candidates, filepath.Join(dir, app.PackageName+".desktop"))
 			}
-			continue
-		}
-
-		if !inDesktopEntry {
-			continue
-		}
-
-		// Parse key=value pairs
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
 		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "Name":
-			name = value
-		case "Exec":
-			exec = value
-		case "Categories":
-			categories = value
-		case "Icon":
-			icon = value
-		case "Type":
-			entryType = value
-		case "NoDisplay":
-			noDisplay = strings.ToLower(value) == "true"
-		case "Hidden":
-			hidden = strings.ToLower(value) == "true"
+		for _, path := range candidates {
+			desktopApp, _, err := parseDesktopFile(path)
+			if err != nil {
+				continue
+			}
+			// A guessed file must launch the same program
+			if app.DesktopFile == "" && desktopApp.PackageName != app.PackageName {
+				continue
+			}
+			app.Exec = desktopApp.Exec
+			app.DesktopFile = desktopApp.DesktopFile
+			app.Terminal = desktopApp.Terminal
+			app.WMClass = desktopApp.WMClass
+			break
 		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
+// parseDesktopFile parses a .desktop file and returns an Application and the raw Categories string
+// Returns nil if the entry should not be displayed (NoDisplay=true, Hidden=true, Type!=Application,
+// excluded by OnlyShowIn/NotShowIn for the current desktop, or TryExec not installed)
+func parseDesktopFile(filePath string) (*Application, string, error) {
+	entry, err := ParseDesktopEntry(filePath)
+	if err != nil {
 		return nil, "", err
 	}
 
+	categories := strings.Join(entry.Categories, ";")
+
 	// Filter: must be Type=Application (or empty, which defaults to Application)
-	if entryType != "" && entryType != "Application" {
-		return nil, "", fmt.Errorf("not an Application type: %s", entryType)
+	if !entry.IsApplication() {
+		return nil, "", fmt.Errorf("not an Application type: %s", entry.Type)
 	}
 
 	// Filter: skip entries marked as NoDisplay or Hidden
-	if noDisplay {
+	if entry.NoDisplay {
 		return nil, "", fmt.Errorf("entry has NoDisplay=true")
 	}
-	if hidden {
+	if entry.Hidden {
 		return nil, "", fmt.Errorf("entry has Hidden=true")
 	}
 
+	// Filter: skip entries not meant for the current desktop
+	if !entry.ShowIn(os.Getenv("XDG_CURRENT_DESKTOP")) {
+		return nil, "", fmt.Errorf("entry not shown in current desktop")
+	}
+
+	// Filter: skip entries whose TryExec binary is not installed
+	if !entry.TryExecAvailable() {
+		return nil, "", fmt.Errorf("TryExec binary not found: %s", entry.TryExec)
+	}
+
 	// Validate required fields
-	if name == "" || exec == "" {
+	if entry.Name == "" || entry.Exec == "" {
 		return nil, "", fmt.Errorf("missing required fields in desktop file")
 	}
 
 	// Extract executable name from Exec field
-	packageName := extractExecutableName(exec)
+	packageName := extractExecutableName(entry.Exec)
 	if packageName == "" {
 		return nil, "", fmt.Errorf("could not extract executable name from Exec field")
 	}
 
-	// Determine category from Categories field
-	category := determineCategory(categories)
-
-	app.Name = name
-	app.PackageName = packageName
-	app.Category = category
-	app.Keybinding = "" // Will be empty for auto-generated apps
-	app.Icon = icon
+	app := &Application{
+		Name:         entry.Name,
+		PackageName:  packageName,
+		Exec:         entry.Exec,
+		DesktopFile:  entry.FilePath,
+		Terminal:     entry.Terminal,
+		WMClass:      entry.StartupWMClass,
+		Category:     determineCategory(categories),
+		Keybindings:  nil, // Will be empty for auto-generated apps
+		Icon:         entry.Icon,
+		CustomConfig: make(map[string]string),
+	}
 
 	return app, categories, nil
 }
 
 // extractExecutableName extracts the base command name from Exec field
-// Exec may contain: "firefox %u", "/usr/bin/gedit", "env VAR=value app", etc.
+// Exec may contain: "firefox %u", "/usr/bin/gedit", "VAR=value app", "\"/opt/My App/app\"", etc.
 func extractExecutableName(execLine string) string {
-	// Remove desktop file field codes like %u, %f, %F, etc.
-	execLine = strings.ReplaceAll(execLine, "%u", "")
-	execLine = strings.ReplaceAll(execLine, "%U", "")
-	execLine = strings.ReplaceAll(execLine, "%f", "")
-	execLine = strings.ReplaceAll(execLine, "%F", "")
-	execLine = strings.ReplaceAll(execLine, "%i", "")
-	execLine = strings.ReplaceAll(execLine, "%c", "")
-	execLine = strings.ReplaceAll(execLine, "%k", "")
-	execLine = strings.TrimSpace(execLine)
-
-	// Split by space to get command and arguments
-	parts := strings.Fields(execLine)
+	// Split into arguments, dropping desktop file field codes like %u, %f, %F, etc.
+	parts := stripFieldCodes(splitCommand(execLine))
 	if len(parts) == 0 {
 		return ""
 	}
@@ -397,7 +393,7 @@ func extractExecutableName(execLine string) string {
 	// Get first part (the command)
 	cmd := parts[0]
 
-	// Handle env VAR=value command format
+	// Handle VAR=value command format
 	if strings.Contains(cmd, "=") {
 		// Find the first part that doesn't contain =
 		for _, part := range parts {
@@ -606,75 +602,129 @@ func formatCategoryName(categoryID string) string {
 	return strings.ToUpper(categoryID[:1]) + categoryID[1:]
 }
 
-// writeConfig writes the configuration to a YAML file
+// UpdateConfig applies change to ~/.config/omarchy.conf.yaml and writes it back
+// change gets the configuration as stored in the file, re-read under the config lock:
+// none of what LoadConfig derives (desktop file metadata, migrated keybindings,
+// keybindings found in the Hyprland config) is in it, so only the change itself is
+// written, and edits made since LoadConfig (by another instance, or a keybinding
+// change) are kept.
+func UpdateConfig(change func(config *OmarchyConfig) error) error {
+	configPath, err := hyprconf.ExpandPath(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to expand config path: %w", err)
+	}
+	return updateConfigFile(configPath, change)
+}
+
+// updateConfigFile applies change to the configuration stored at configPath
+func updateConfigFile(configPath string, change func(config *OmarchyConfig) error) error {
+	unlock, err := fsutil.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config OmarchyConfig
+	if err := yaml.Unmarshal(original, &config); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if err := change(&config); err != nil {
+		return err
+	}
+
+	data, err := marshalPreserving(original, &config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// writeConfig writes a whole configuration (e.g. the one generated on first run) to a
+// YAML file, preserving the existing file's comments, key order, anchors and unknown fields
 func writeConfig(configPath string, config *OmarchyConfig) error {
+	unlock, err := fsutil.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(configPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(config)
+	// Read the existing file so comments, ordering and unknown fields are preserved
+	original, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Marshal config to YAML on top of the existing document
+	data, err := marshalPreserving(original, config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// Write to file atomically so a crash never leaves a truncated config
+	if err := fsutil.WriteFileAtomic(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
-// updateKeybindingsFromHypr updates keybindings in config from Hyprland bindings.conf
+// updateKeybindingsFromHypr updates keybindings in config from the Hyprland config
+// It starts at hyprland.conf and follows its source= includes, so binds from the
+// default Omarchy files are found too. Falls back to bindings.conf on its own.
 func updateKeybindingsFromHypr(config *OmarchyConfig) error {
 	logger.Log("updateKeybindingsFromHypr: Entering function")
-	hyprPath, err := expandPath("~/.config/hypr/bindings.conf")
+
+	hyprConfig, err := LoadHyprConfig()
 	if err != nil {
-		return fmt.Errorf("failed to expand hypr config path: %w", err)
+		return err
 	}
-
-	// Check if file exists
-	if _, err := os.Stat(hyprPath); os.IsNotExist(err) {
-		// File doesn't exist, that's okay - just return
-		logger.Log("updateKeybindingsFromHypr: Hyprland bindings.conf not found at %s", hyprPath)
+	if hyprConfig == nil {
+		// No Hyprland config, that's okay - just return
 		return nil
 	}
-	logger.Log("updateKeybindingsFromHypr: Found Hyprland bindings.conf at %s", hyprPath)
-
-	// Extract keybindings from Hyprland config
-	keybindings, err := extractKeybindingsFromHypr(hyprPath)
-	if err != nil {
-		return fmt.Errorf("failed to extract keybindings: %w", err)
+	for _, parseErr := range hyprConfig.Errors {
+		logger.Log("updateKeybindingsFromHypr: %v", parseErr)
 	}
-	logger.Log("updateKeybindingsFromHypr: Extracted %d keybindings", len(keybindings))
+	logger.Log("updateKeybindingsFromHypr: Parsed %d Hyprland config files", len(hyprConfig.Files))
+
+	// Extract keybindings from Hyprland config and match them to apps
+	matched := extractKeybindingsFromHypr(hyprConfig, config.AppsInventory)
+	logger.Log("updateKeybindingsFromHypr: Matched keybindings for %d apps", len(matched))
 
-	// Update apps with matching keybindings
+	// Add the matched keybindings the config doesn't record yet; they are never saved,
+	// so they are found again on every load
 	updatedCount := 0
 	for i := range config.AppsInventory {
 		app := &config.AppsInventory[i]
-		// Only update if keybinding is empty
-		if app.Keybinding == "" {
-			// Try exact match (case-insensitive)
-			appNameLower := strings.ToLower(app.Name)
-			if keybinding, found := keybindings[appNameLower]; found {
-				app.Keybinding = keybinding
-				logger.Log("updateKeybindingsFromHypr: Matched app '%s' with keybinding '%s' (exact match)", app.Name, keybinding)
-				updatedCount++
+		recorded := make(map[string]bool)
+		for _, binding := range app.Keybindings {
+			recorded[strings.ToLower(app.KeybindingLabel(binding))] = true
+		}
+		added := false
+		for _, binding := range matched[i] {
+			if recorded[strings.ToLower(app.KeybindingLabel(binding))] {
 				continue
 			}
-
-			// Try partial match - check if app name contains any keybinding key
-			for keybindingAppName, keybinding := range keybindings {
-				if strings.Contains(appNameLower, keybindingAppName) || strings.Contains(keybindingAppName, appNameLower) {
-					app.Keybinding = keybinding
-					logger.Log("updateKeybindingsFromHypr: Matched app '%s' with keybinding '%s' (partial match with '%s')", app.Name, keybinding, keybindingAppName)
-					updatedCount++
-					break
-				}
-			}
+			app.Keybindings = append(app.Keybindings, binding)
+			added = true
+			logger.Log("updateKeybindingsFromHypr: Matched app '%s' with keybinding '%s' by %s", app.Name, binding.Keys, binding.Match)
+		}
+		if added {
+			updatedCount++
 		}
 	}
 	logger.Log("updateKeybindingsFromHypr: Updated %d apps with keybindings", updatedCount)
@@ -682,138 +732,88 @@ func updateKeybindingsFromHypr(config *OmarchyConfig) error {
 	return nil
 }
 
-// extractKeybindingsFromHypr parses Hyprland bindings.conf and returns a map of app name -> keybinding
-func extractKeybindingsFromHypr(configPath string) (map[string]string, error) {
-	file, err := os.Open(configPath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+// LoadHyprConfig parses the Hyprland config, returning nil if none exists
+// It starts at hyprland.conf and falls back to bindings.conf on its own.
+func LoadHyprConfig() (*hyprconf.Config, error) {
+	for _, candidate := range []string{hyprconf.DefaultConfigPath, "~/.config/hypr/bindings.conf"} {
+		hyprPath, err := hyprconf.ExpandPath(candidate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand hypr config path: %w", err)
+		}
 
-	scanner := bufio.NewScanner(file)
-	keybindings := make(map[string]string)
-	mainMod := "SUPER" // default
+		// Check if file exists
+		if _, err := os.Stat(hyprPath); os.IsNotExist(err) {
+			logger.Log("LoadHyprConfig: Hyprland config not found at %s", hyprPath)
+			continue
+		}
+		logger.Log("LoadHyprConfig: Found Hyprland config at %s", hyprPath)
 
-	// First pass: find mainMod variable
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "$mainMod") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				mainMod = strings.TrimSpace(parts[1])
-			}
+		hyprConfig, err := hyprconf.ParseFile(hyprPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Hyprland config: %w", err)
 		}
+		return hyprConfig, nil
 	}
+	return nil, nil
+}
 
-	// Reset file for second pass
-	file.Seek(0, 0)
-	scanner = bufio.NewScanner(file)
-
-	// Second pass: parse bind lines
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
+// extractKeybindingsFromHypr returns the keybindings of the parsed exec binds, keyed by the
+// index in apps of the app each one launches
+// Every bind flavor (bind, bindd, bindel, bindm, ...) still in effect is considered. Each
+// bind goes to the app it matches with the highest confidence (see matchBind); binds
+// matching no app are skipped. An executable several apps run (see sharedExecutables)
+// doesn't match by itself.
+func extractKeybindingsFromHypr(hyprConfig *hyprconf.Config, apps []Application) map[int][]AppKeybinding {
+	keybindings := make(map[int][]AppKeybinding)
+
+	shared := sharedExecutables(apps)
+
+	// Binds removed by unbind or living in a submap don't count
+	for _, bind := range hyprConfig.ActiveBinds() {
+		keybinding, err := parseHyprBind(bind)
+		if err != nil || !keybinding.IsValid() {
 			continue
 		}
 
-		// Check for bind lines (including bindd format)
-		if strings.HasPrefix(line, "bindd =") || strings.HasPrefix(line, "bind =") || strings.HasPrefix(line, "bindr =") || strings.HasPrefix(line, "bindl =") {
-			keybinding, appName, err := parseHyprBindLine(line, mainMod)
-			if err != nil {
-				// Skip invalid lines
-				continue
-			}
-			if appName != "" && keybinding != "" {
-				keybindings[strings.ToLower(appName)] = keybinding
+		command := resolveBindCommand(bind.Args)
+		best, bestIndex := (*BindMatch)(nil), -1
+		for i := range apps {
+			if match := matchBind(&apps[i], bind, command, shared); match != nil && match.better(best) {
+				best, bestIndex = match, i
 			}
 		}
-	}
+		if bestIndex < 0 {
+			continue
+		}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		binding := AppKeybinding{
+			Keys:    keybinding,
+			Command: bind.Args,
+			Flags:   string(bind.Flags),
+			Match:   best,
+		}
+		if bind.HasDescription() && !strings.EqualFold(bind.Description, apps[bestIndex].Name) {
+			binding.Label = bind.Description
+		}
+		keybindings[bestIndex] = append(keybindings[bestIndex], binding)
 	}
 
-	return keybindings, nil
+	return keybindings
 }
 
-// parseHyprBindLine parses a single bind line and returns keybinding (in Hyprland format) and app name
-func parseHyprBindLine(line string, mainMod string) (keybinding, appName string, err error) {
-	// Format: bindd = MODIFIERS, KEY, LABEL, exec, COMMAND
-	// Example: bindd = $mainMod SHIFT, A, ChatGPT, exec, omarchy-launch-webapp "https://chatgpt.com"
-	// Returns keybinding in Hyprland format: "MODIFIERS, KEY" (e.g., "SUPER SHIFT, A")
-
-	// Remove "bindd =", "bind =", "bindr =", or "bindl ="
-	line = strings.TrimPrefix(line, "bindd =")
-	line = strings.TrimPrefix(line, "bind =")
-	line = strings.TrimPrefix(line, "bindr =")
-	line = strings.TrimPrefix(line, "bindl =")
-	line = strings.TrimSpace(line)
-
-	// Split by comma
-	parts := strings.Split(line, ",")
-	if len(parts) < 4 {
-		return "", "", fmt.Errorf("invalid bind line format")
-	}
-
-	// Extract modifiers and key
-	modifiers := strings.TrimSpace(parts[0])
-	key := strings.TrimSpace(parts[1])
-	thirdPart := strings.TrimSpace(parts[2])
-
-	// Check if third part is "exec" (old format) or a label (bindd format)
-	if thirdPart == "exec" {
-		// Old format: MODIFIERS, KEY, exec, COMMAND
-		if len(parts) < 4 {
-			return "", "", fmt.Errorf("missing command")
-		}
-		command := strings.TrimSpace(parts[3])
-		// Extract app name from command for old format
-		appName = extractAppNameFromCommand(command)
-	} else if len(parts) >= 5 {
-		// bindd format: MODIFIERS, KEY, LABEL, exec, COMMAND
-		label := thirdPart
-		execCmd := strings.TrimSpace(parts[3])
-		if execCmd != "exec" {
-			return "", "", fmt.Errorf("not an exec command")
-		}
-		// Use LABEL as app name (this is what we match against app.Name in config)
-		appName = label
-	} else {
-		return "", "", fmt.Errorf("not an exec command or invalid format")
-	}
-
-	// Format keybinding in Hyprland format: "MODIFIERS, KEY"
-	// Replace $mainMod with actual value
-	modifiers = strings.ReplaceAll(modifiers, "$mainMod", mainMod)
-	modifiers = strings.TrimSpace(modifiers)
-	// Format as "MODIFIERS, KEY" (Hyprland format)
-	keybinding = fmt.Sprintf("%s, %s", modifiers, key)
-
-	return keybinding, appName, nil
-}
-
-// extractAppNameFromCommand extracts application name from exec command
-func extractAppNameFromCommand(command string) string {
-	logger.Log("extractAppNameFromCommand: Entering function, command: %s", command)
-	// Remove common prefixes and extract base name
-	command = strings.TrimSpace(command)
-
-	// Handle paths: /usr/bin/firefox -> firefox
-	if strings.Contains(command, "/") {
-		command = filepath.Base(command)
+// parseHyprBind returns the keybinding of an exec bind
+// Example: bindd = SUPER SHIFT, A, ChatGPT, exec, omarchy-launch-webapp "https://chatgpt.com"
+// returns "SUPER SHIFT, A"
+func parseHyprBind(bind *hyprconf.Bind) (Keybinding, error) {
+	if !bind.IsExec() {
+		return Keybinding{}, fmt.Errorf("not an exec command")
 	}
 
-	// Remove common suffixes and arguments
-	parts := strings.Fields(command)
-	if len(parts) > 0 {
-		appName := parts[0]
-		// Remove file extensions if any
-		appName = strings.TrimSuffix(appName, ".sh")
-		appName = strings.TrimSuffix(appName, ".exe")
-		return appName
+	// Parse "MODIFIERS, KEY" (Hyprland format)
+	keybinding, err := ParseKeybinding(fmt.Sprintf("%s, %s", bind.Mods, bind.Key))
+	if err != nil {
+		return Keybinding{}, err
 	}
 
-	return command
+	return keybinding.Canonical(), nil
 }
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFillDesktopMetadata(t *testing.T) {
+	dataDir := t.TempDir()
+	appsDir := filepath.Join(dataDir, "applications")
+	if err := os.MkdirAll(appsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(appsDir, "zed.desktop"), "[Desktop Entry]\nName=Zed\nExec=zed %U\nStartupWMClass=dev.zed.Zed\n")
+	write(filepath.Join(appsDir, "other.desktop"), "[Desktop Entry]\nName=Other\nExec=something-else\n")
+	named := filepath.Join(t.TempDir(), "named.desktop")
+	write(named, "[Desktop Entry]\nName=Named\nExec=named --flag\nTerminal=true\n")
+	t.Setenv("XDG_DATA_DIRS", dataDir)
+
+	config := &OmarchyConfig{AppsInventory: []Application{
+		{Name: "Zed", PackageName: "zed"},
+		{Name: "Named", PackageName: "named", DesktopFile: named},
+		{Name: "Other", PackageName: "other"}, // other.desktop runs a different program
+		{Name: "Custom", PackageName: "my-script"},
+		{Name: "Kept", PackageName: "zed", Exec: "zed --kept"},
+	}}
+	fillDesktopMetadata(config)
+
+	tests := []struct {
+		app      int
+		exec     string
+		terminal bool
+		wmClass  string
+	}{
+		{0, "zed %U", false, "dev.zed.Zed"},
+		{1, "named --flag", true, ""},
+		{2, "", false, ""},
+		{3, "", false, ""},
+		{4, "zed --kept", false, ""},
+	}
+	for _, tt := range tests {
+		app := config.AppsInventory[tt.app]
+		if app.Exec != tt.exec || app.Terminal != tt.terminal || app.WMClass != tt.wmClass {
+			t.Errorf("%s: got Exec %q Terminal %v WMClass %q, want %q %v %q",
+				app.Name, app.Exec, app.Terminal, app.WMClass, tt.exec, tt.terminal, tt.wmClass)
+		}
+	}
+}
+
+func TestValidateConfigDropsStaleDefaults(t *testing.T) {
+	config := &OmarchyConfig{
+		Categories:    []Category{{ID: "editor", Name: "Editor"}},
+		AppsInventory: []Application{{Name: "Zed", PackageName: "zed", Category: "editor"}},
+		Defaults: map[string]DefaultApp{
+			"editor":  {Name: "Zed", PackageName: "zed"},
+			"removed": {Name: "Gone", PackageName: "gone"},
+		},
+	}
+	if err := validateConfig(config); err != nil {
+		t.Fatalf("validateConfig: %v", err)
+	}
+	if _, ok := config.Defaults["removed"]; ok {
+		t.Error("default of unknown category was kept")
+	}
+	if _, ok := config.Defaults["editor"]; !ok {
+		t.Error("valid default was dropped")
+	}
+}
+
+const roundTripConfig = `# omarchy-tui configuration
+categories:
+    - id: editor
+      name: Editor
+    - id: network
+      name: Network # browsers
+apps_inventory:
+    - name: Zed
+      package_name: zed
+      category: editor
+    - name: Firefox
+      package_name: firefox
+      category: network
+      keybinding: "SUPER, B"   # browser
+      x-note: kept
+defaults:
+    editor:
+        name: Zed
+        package_name: zed
+`
+
+// changedLines returns the lines of after that differ from before, which must have the same length
+func changedLines(t *testing.T, before, after string) []string {
+	t.Helper()
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+	if len(a) != len(b) {
+		t.Fatalf("line count changed from %d to %d:\n%s", len(a), len(b), after)
+	}
+	var changed []string
+	for i := range a {
+		if a[i] != b[i] {
+			changed = append(changed, b[i])
+		}
+	}
+	return changed
+}
+
+func TestUpdateConfigFileChangesOnlyTheEdit(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "omarchy.conf.yaml")
+	if err := os.WriteFile(path, []byte(roundTripConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := updateConfigFile(path, func(config *OmarchyConfig) error {
+		def := config.Defaults["editor"]
+		def.PackageName = "zeditor"
+		config.Defaults["editor"] = def
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateConfigFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := changedLines(t, roundTripConfig, string(data))
+	if len(changed) != 1 || changed[0] != "        package_name: zeditor" {
+		t.Errorf("changed lines = %q, want only the edited scalar", changed)
+	}
+}
+
+func TestUpdateConfigFileKeepsLegacyKeybinding(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "omarchy.conf.yaml")
+	if err := os.WriteFile(path, []byte(roundTripConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting a default must not write what LoadConfig derives for other apps
+	err := updateConfigFile(path, func(config *OmarchyConfig) error {
+		config.SetDefaultApp("network", &config.AppsInventory[1])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateConfigFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := roundTripConfig + "    network:\n        name: Firefox\n        package_name: firefox\n"
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestUpdateConfigFileKeepsChangesMadeOnDisk(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "omarchy.conf.yaml")
+	if err := os.WriteFile(path, []byte(roundTripConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// This instance loaded the config before another one changed a keybinding
+	var stale OmarchyConfig
+	if err := yaml.Unmarshal([]byte(roundTripConfig), &stale); err != nil {
+		t.Fatal(err)
+	}
+	err := updateConfigFile(path, func(config *OmarchyConfig) error {
+		config.AppsInventory[0].SetKeybinding(AppKeybinding{Keys: mustParseKeybinding(t, "SUPER, E")})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateConfigFile: %v", err)
+	}
+
+	err = updateConfigFile(path, func(config *OmarchyConfig) error {
+		config.SetDefaultApp("network", &stale.AppsInventory[1])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateConfigFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config OmarchyConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.AppsInventory[0].Keybindings) != 1 {
+		t.Errorf("keybinding written on disk was lost: %+v", config.AppsInventory[0])
+	}
+	if _, ok := config.Defaults["network"]; !ok {
+		t.Error("default was not saved")
+	}
+}
+
+func TestUpdateConfigFileDropsBindMatch(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "omarchy.conf.yaml")
+	if err := os.WriteFile(path, []byte(roundTripConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := updateConfigFile(path, func(config *OmarchyConfig) error {
+		config.AppsInventory[0].SetKeybinding(AppKeybinding{
+			Keys:  mustParseKeybinding(t, "SUPER, E"),
+			Match: &BindMatch{Rule: MatchExecutable, Confidence: ConfidenceMedium, Value: "zed"},
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updateConfigFile: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "match") || strings.Contains(string(data), "confidence") {
+		t.Errorf("derived match was saved:\n%s", data)
+	}
+	if !strings.Contains(string(data), "keys: SUPER, E") {
+		t.Errorf("keybinding not saved:\n%s", data)
+	}
+}
+
+// mustParseKeybinding parses text or fails the test
+func mustParseKeybinding(t *testing.T, text string) Keybinding {
+	t.Helper()
+	kb, err := ParseKeybinding(text)
+	if err != nil {
+		t.Fatalf("ParseKeybinding(%q): %v", text, err)
+	}
+	return kb
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"omarchy-tui/internal/fsutil"
+	"omarchy-tui/internal/hyprconf"
+	"omarchy-tui/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultApplicationsGroup is the mimeapps.list group holding default handlers
+const defaultApplicationsGroup = "Default Applications"
+
+// DefaultMimeAssociations maps category IDs, as assigned by the desktop file scan,
+// to the MIME types their default app should handle. Used when the config has no
+// mime_associations section.
+var DefaultMimeAssociations = map[string][]string{
+	"network": {
+		"text/html",
+		"application/xhtml+xml",
+		"x-scheme-handler/http",
+		"x-scheme-handler/https",
+	},
+}
+
+// GetMimeTypes returns the MIME types associated with a category's default app
+func (c *OmarchyConfig) GetMimeTypes(categoryID string) []string {
+	if c.MimeAssociations != nil {
+		return c.MimeAssociations[categoryID]
+	}
+	return DefaultMimeAssociations[categoryID]
+}
+
+// MimeApps is an in-memory mimeapps.list that keeps every line it does not modify,
+// so unrelated groups, comments and ordering survive a save
+type MimeApps struct {
+	lines []string
+}
+
+// mimeAppsPath returns $XDG_CONFIG_HOME/mimeapps.list (default ~/.config/mimeapps.list)
+func mimeAppsPath() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "mimeapps.list"), nil
+	}
+	return hyprconf.ExpandPath("~/.config/mimeapps.list")
+}
+
+// LoadMimeApps reads a mimeapps.list file; a missing file yields an empty list
+func LoadMimeApps(path string) (*MimeApps, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &MimeApps{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return &MimeApps{}, nil
+	}
+	return &MimeApps{lines: strings.Split(content, "\n")}, nil
+}
+
+// groupRange returns the index of the group header and the end (exclusive) of its body
+func (m *MimeApps) groupRange(group string) (header, end int) {
+	header = -1
+	for i, line := range m.lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+			continue
+		}
+		if header >= 0 {
+			return header, i
+		}
+		if trimmed == "["+group+"]" {
+			header = i
+		}
+	}
+	return header, len(m.lines)
+}
+
+// GetDefault returns the desktop IDs registered as default for mimeType
+func (m *MimeApps) GetDefault(mimeType string) []string {
+	header, end := m.groupRange(defaultApplicationsGroup)
+	if header < 0 {
+		return nil
+	}
+	for _, line := range m.lines[header+1 : end] {
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == mimeType {
+			return splitDesktopList(strings.TrimSpace(value))
+		}
+	}
+	return nil
+}
+
+// SetDefault makes desktopID the default handler for mimeType
+// Other handlers already listed for the type are kept as fallbacks after it.
+func (m *MimeApps) SetDefault(mimeType, desktopID string) {
+	ids := []string{desktopID}
+	for _, id := range m.GetDefault(mimeType) {
+		if id != desktopID {
+			ids = append(ids, id)
+		}
+	}
+	newLine := fmt.Sprintf("%s=%s;", mimeType, strings.Join(ids, ";"))
+
+	header, end := m.groupRange(defaultApplicationsGroup)
+	if header < 0 {
+		if len(m.lines) > 0 && strings.TrimSpace(m.lines[len(m.lines)-1]) != "" {
+			m.lines = append(m.lines, "")
+		}
+		m.lines = append(m.lines, "["+defaultApplicationsGroup+"]", newLine)
+		return
+	}
+
+	for i := header + 1; i < end; i++ {
+		key, _, ok := strings.Cut(m.lines[i], "=")
+		if ok && strings.TrimSpace(key) == mimeType {
+			m.lines[i] = newLine
+			return
+		}
+	}
+
+	// Insert after the last non-empty line of the group
+	insertAt := end
+	for insertAt > header+1 && strings.TrimSpace(m.lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	m.lines = append(m.lines[:insertAt], append([]string{newLine}, m.lines[insertAt:]...)...)
+}
+
+// Save writes the list back to path
+func (m *MimeApps) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, []byte(strings.Join(m.lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write mimeapps.list: %w", err)
+	}
+	return nil
+}
+
+// SyncMimeDefaults writes the MIME associations of a category's default app into mimeapps.list
+// Only MIME types the app declares in its desktop file are associated.
+func SyncMimeDefaults(config *OmarchyConfig, categoryID string) error {
+	mimeTypes := config.GetMimeTypes(categoryID)
+	if len(mimeTypes) == 0 {
+		return nil
+	}
+
+	app := config.GetDefaultApp(categoryID)
+	if app == nil {
+		return nil
+	}
+	if app.DesktopFile == "" {
+		logger.Log("SyncMimeDefaults: App '%s' has no desktop file, skipping MIME associations", app.Name)
+		return nil
+	}
+
+	entry, err := ParseDesktopEntry(app.DesktopFile)
+	if err != nil {
+		return fmt.Errorf("failed to read desktop file: %w", err)
+	}
+	supported := make(map[string]bool)
+	for _, mimeType := range entry.MimeType {
+		supported[mimeType] = true
+	}
+
+	path, err := mimeAppsPath()
+	if err != nil {
+		return fmt.Errorf("failed to expand mimeapps.list path: %w", err)
+	}
+
+	unlock, err := fsutil.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	mimeApps, err := LoadMimeApps(path)
+	if err != nil {
+		return fmt.Errorf("failed to read mimeapps.list: %w", err)
+	}
+
+	updated := 0
+	for _, mimeType := range mimeTypes {
+		if !supported[mimeType] {
+			logger.Log("SyncMimeDefaults: App '%s' does not declare %s, skipping", app.Name, mimeType)
+			continue
+		}
+		mimeApps.SetDefault(mimeType, entry.ID)
+		updated++
+	}
+	if updated == 0 {
+		return nil
+	}
+
+	logger.Log("SyncMimeDefaults: Associated %d MIME types with %s", updated, entry.ID)
+	return mimeApps.Save(path)
+}
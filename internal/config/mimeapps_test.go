@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadMimeAppsString writes content to a temporary mimeapps.list and loads it
+func loadMimeAppsString(t *testing.T, content string) (*MimeApps, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mimeapps.list")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mimeApps, err := LoadMimeApps(path)
+	if err != nil {
+		t.Fatalf("LoadMimeApps: %v", err)
+	}
+	return mimeApps, path
+}
+
+func TestMimeAppsSetDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		mimeType string
+		id       string
+		want     string
+	}{
+		{
+			"missing file",
+			"",
+			"text/html", "chromium.desktop",
+			"[Default Applications]\ntext/html=chromium.desktop;\n",
+		},
+		{
+			"no default group",
+			"[Added Associations]\ntext/html=firefox.desktop;\n",
+			"text/html", "chromium.desktop",
+			"[Added Associations]\ntext/html=firefox.desktop;\n\n[Default Applications]\ntext/html=chromium.desktop;\n",
+		},
+		{
+			"existing key replaced in place, old handler kept as fallback",
+			"[Default Applications]\n# browsers\ntext/html=firefox.desktop;\nimage/png=imv.desktop;\n",
+			"text/html", "chromium.desktop",
+			"[Default Applications]\n# browsers\ntext/html=chromium.desktop;firefox.desktop;\nimage/png=imv.desktop;\n",
+		},
+		{
+			"already the default",
+			"[Default Applications]\ntext/html=firefox.desktop;chromium.desktop;\n",
+			"text/html", "chromium.desktop",
+			"[Default Applications]\ntext/html=chromium.desktop;firefox.desktop;\n",
+		},
+		{
+			"new key appended to the group, other groups untouched",
+			"[Default Applications]\nimage/png=imv.desktop;\n\n[Added Associations]\ntext/html=firefox.desktop;\n",
+			"text/html", "chromium.desktop",
+			"[Default Applications]\nimage/png=imv.desktop;\ntext/html=chromium.desktop;\n\n[Added Associations]\ntext/html=firefox.desktop;\n",
+		},
+		{
+			"same key in another group is not the default",
+			"[Added Associations]\ntext/html=firefox.desktop;\n[Default Applications]\n",
+			"text/html", "chromium.desktop",
+			"[Added Associations]\ntext/html=firefox.desktop;\n[Default Applications]\ntext/html=chromium.desktop;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeApps, path := loadMimeAppsString(t, tt.content)
+			mimeApps.SetDefault(tt.mimeType, tt.id)
+			if err := mimeApps.Save(path); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("saved:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMimeAppsRoundTrip(t *testing.T) {
+	content := "# managed by hand\n[Added Associations]\nimage/png=imv.desktop;\n\n[Default Applications]\ntext/html=firefox.desktop;chromium.desktop;\nx-scheme-handler/http = firefox.desktop;\n\n[Removed Associations]\ntext/plain=nvim.desktop;\n"
+	mimeApps, path := loadMimeAppsString(t, content)
+
+	if got, want := mimeApps.GetDefault("text/html"), []string{"firefox.desktop", "chromium.desktop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDefault(text/html) = %q, want %q", got, want)
+	}
+	if got, want := mimeApps.GetDefault("x-scheme-handler/http"), []string{"firefox.desktop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDefault(x-scheme-handler/http) = %q, want %q", got, want)
+	}
+	if got := mimeApps.GetDefault("image/png"); got != nil {
+		t.Errorf("GetDefault(image/png) = %q, want nothing from [Added Associations]", got)
+	}
+
+	// Unmodified lists are written back byte for byte
+	if err := mimeApps.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("saved:\n%s\nwant:\n%s", got, content)
+	}
+
+	reloaded, err := LoadMimeApps(path)
+	if err != nil {
+		t.Fatalf("LoadMimeApps: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.lines, mimeApps.lines) {
+		t.Errorf("reloaded lines = %q, want %q", reloaded.lines, mimeApps.lines)
+	}
+}
+
+func TestDefaultMimeAssociationsUseCategoryIDs(t *testing.T) {
+	for categoryID := range DefaultMimeAssociations {
+		if _, ok := categoryDisplayNames[categoryID]; !ok {
+			t.Errorf("DefaultMimeAssociations key %q is not a category ID the desktop file scan assigns", categoryID)
+		}
+	}
+	if got := determineCategory("Network;WebBrowser;"); len(DefaultMimeAssociations[got]) == 0 {
+		t.Errorf("web browsers land in category %q, which has no default MIME types", got)
+	}
+}
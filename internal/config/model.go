@@ -1,5 +1,10 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Category represents an application category
 type Category struct {
 	ID   string `yaml:"id"`
@@ -8,19 +13,53 @@ type Category struct {
 
 // Application represents an application entry
 type Application struct {
-	Name         string            `yaml:"name"`
-	PackageName  string            `yaml:"package_name"`
-	Keybinding   string            `yaml:"keybinding"`
-	Category     string            `yaml:"category"`
-	ConfigFile   string            `yaml:"config_file,omitempty"`
-	Icon         string            `yaml:"icon,omitempty"`
-	CustomConfig map[string]string `yaml:"custom_config,omitempty"`
+	Name           string            `yaml:"name"`
+	PackageName    string            `yaml:"package_name"`
+	Exec           string            `yaml:"exec,omitempty"`            // full Exec line from the desktop file
+	DesktopFile    string            `yaml:"desktop_file,omitempty"`    // path of the originating .desktop file
+	Terminal       bool              `yaml:"terminal,omitempty"`        // run inside a terminal emulator (Terminal=true)
+	WMClass        string            `yaml:"wm_class,omitempty"`        // window class from StartupWMClass, used by window rules
+	Placement      Placement         `yaml:"placement,omitempty"`       // workspace/monitor/floating to open the app with
+	SingleInstance bool              `yaml:"single_instance,omitempty"` // focus an open window instead of starting a second copy
+	WindowTitle    string            `yaml:"window_title,omitempty"`    // initialTitle matched in single-instance mode, besides the class
+	Keybinding     Keybinding        `yaml:"keybinding,omitempty"`      // legacy single keybinding, migrated to Keybindings on load
+	Keybindings    []AppKeybinding   `yaml:"keybindings,omitempty"`     // every bind that reaches the app
+	Category       string            `yaml:"category"`
+	ConfigFile     string            `yaml:"config_file,omitempty"`
+	Icon           string            `yaml:"icon,omitempty"`
+	CustomConfig   map[string]string `yaml:"custom_config,omitempty"`
+}
+
+// AppKeybinding is one Hyprland bind that reaches an application
+// An app can have several: a launch bind, a focus-or-launch bind, a bind opening a URL...
+type AppKeybinding struct {
+	Keys    Keybinding `yaml:"keys"`
+	Label   string     `yaml:"label,omitempty"`   // bind description, identifies the bind; defaults to the app name
+	Command string     `yaml:"command,omitempty"` // exec command; defaults to the package name
+	Flags   string     `yaml:"flags,omitempty"`   // bind flags, e.g. "d" for bindd
+	Match   *BindMatch `yaml:"-"`                 // how a bind read from the Hyprland config was matched to the app; derived, never saved
+}
+
+// DefaultApp records the default application of a category
+// Name identifies the app in apps_inventory; PackageName and Exec are kept so
+// scripts can launch the default without looking it up.
+type DefaultApp struct {
+	Name        string `yaml:"name"`
+	PackageName string `yaml:"package_name"`
+	Exec        string `yaml:"exec,omitempty"`
 }
 
 // OmarchyConfig is the root configuration structure
 type OmarchyConfig struct {
-	Categories    []Category    `yaml:"categories"`
-	AppsInventory []Application `yaml:"apps_inventory"`
+	Categories    []Category            `yaml:"categories"`
+	AppsInventory []Application         `yaml:"apps_inventory"`
+	Defaults      map[string]DefaultApp `yaml:"defaults,omitempty"` // category ID -> default app
+	// MimeAssociations maps category IDs to the MIME types written to mimeapps.list
+	// for their default app; nil uses DefaultMimeAssociations
+	MimeAssociations map[string][]string `yaml:"mime_associations,omitempty"`
+	// HyprVariables maps category IDs to Hyprland variables (e.g. network -> browser)
+	// that are rewritten in bindings.conf when the category default changes
+	HyprVariables map[string]string `yaml:"hypr_variables,omitempty"`
 }
 
 // GetAppsByCategory returns all applications for a given category ID
@@ -43,3 +82,105 @@ func (c *OmarchyConfig) GetCategoryByID(categoryID string) *Category {
 	}
 	return nil
 }
+
+// GetDefaultApp returns the default application for a category, or nil if none is set
+func (c *OmarchyConfig) GetDefaultApp(categoryID string) *Application {
+	def, ok := c.Defaults[categoryID]
+	if !ok {
+		return nil
+	}
+	for i := range c.AppsInventory {
+		app := &c.AppsInventory[i]
+		if app.Name == def.Name && app.PackageName == def.PackageName {
+			return app
+		}
+	}
+	return nil
+}
+
+// SetDefaultApp records app as the default application for a category
+func (c *OmarchyConfig) SetDefaultApp(categoryID string, app *Application) {
+	if c.Defaults == nil {
+		c.Defaults = make(map[string]DefaultApp)
+	}
+	c.Defaults[categoryID] = DefaultApp{
+		Name:        app.Name,
+		PackageName: app.PackageName,
+		Exec:        app.Exec,
+	}
+}
+
+// WindowClass returns the class Hyprland sees on the app's windows: StartupWMClass if
+// the desktop file has one, otherwise the package name
+func (a *Application) WindowClass() string {
+	if a.WMClass != "" {
+		return a.WMClass
+	}
+	return a.PackageName
+}
+
+// KeybindingLabel returns the label identifying binding, defaulting to the app name
+func (a *Application) KeybindingLabel(binding AppKeybinding) string {
+	if binding.Label != "" {
+		return binding.Label
+	}
+	return a.Name
+}
+
+// FindKeybinding returns the index of the binding with label ("" for the app name), or -1
+func (a *Application) FindKeybinding(label string) int {
+	if label == "" {
+		label = a.Name
+	}
+	for i, binding := range a.Keybindings {
+		if strings.EqualFold(a.KeybindingLabel(binding), label) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetKeybinding adds or replaces the binding with the same label
+// A binding without keys is removed instead. A legacy single keybinding is moved
+// into Keybindings first.
+func (a *Application) SetKeybinding(binding AppKeybinding) {
+	a.migrateKeybinding()
+	if strings.EqualFold(binding.Label, a.Name) {
+		binding.Label = ""
+	}
+
+	index := a.FindKeybinding(binding.Label)
+	switch {
+	case binding.Keys.IsZero() && index >= 0:
+		a.Keybindings = append(a.Keybindings[:index], a.Keybindings[index+1:]...)
+	case binding.Keys.IsZero():
+	case index >= 0:
+		a.Keybindings[index] = binding
+	default:
+		a.Keybindings = append(a.Keybindings, binding)
+	}
+}
+
+// NewKeybindingLabel returns an unused label for an additional binding
+func (a *Application) NewKeybindingLabel() string {
+	if a.FindKeybinding("") < 0 {
+		return a.Name
+	}
+	for n := 2; ; n++ {
+		label := fmt.Sprintf("%s %d", a.Name, n)
+		if a.FindKeybinding(label) < 0 {
+			return label
+		}
+	}
+}
+
+// migrateKeybinding moves a legacy single keybinding into Keybindings
+func (a *Application) migrateKeybinding() {
+	if a.Keybinding.IsZero() {
+		return
+	}
+	if len(a.Keybindings) == 0 {
+		a.Keybindings = []AppKeybinding{{Keys: a.Keybinding}}
+	}
+	a.Keybinding = Keybinding{}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placement says where Hyprland opens an app's windows
+// It becomes the rule block of Hyprland's exec dispatcher, e.g.
+// "[workspace 2 silent; float] firefox".
+type Placement struct {
+	Workspace string `yaml:"workspace,omitempty"` // workspace ID or name, e.g. "2" or "special:music"
+	Silent    bool   `yaml:"silent,omitempty"`    // open on the workspace without switching to it
+	Monitor   string `yaml:"monitor,omitempty"`   // monitor name or ID, e.g. "DP-1"
+	Floating  bool   `yaml:"floating,omitempty"`
+}
+
+// IsZero reports whether no placement is set
+func (p Placement) IsZero() bool {
+	return p.Workspace == "" && p.Monitor == "" && !p.Floating
+}
+
+// Rules returns the exec rules of the placement, e.g. "workspace 2 silent; float"
+func (p Placement) Rules() string {
+	var rules []string
+	if p.Workspace != "" {
+		rule := "workspace " + p.Workspace
+		if p.Silent {
+			rule += " silent"
+		}
+		rules = append(rules, rule)
+	}
+	if p.Monitor != "" {
+		rules = append(rules, "monitor "+p.Monitor)
+	}
+	if p.Floating {
+		rules = append(rules, "float")
+	}
+	return strings.Join(rules, "; ")
+}
+
+// String describes the placement for display, e.g. "workspace 2 silent, float"
+func (p Placement) String() string {
+	return strings.ReplaceAll(p.Rules(), "; ", ", ")
+}
+
+// Apply returns command with the placement's rule block in front, replacing any
+// rule block it already has. A zero placement leaves command untouched.
+func (p Placement) Apply(command string) string {
+	if p.IsZero() {
+		return command
+	}
+	_, rest := SplitExecRules(command)
+	return fmt.Sprintf("[%s] %s", p.Rules(), rest)
+}
+
+// SplitExecRules splits a leading "[rules]" block off an exec command
+// Example: "[workspace 2 silent] firefox" returns "workspace 2 silent" and "firefox".
+func SplitExecRules(command string) (rules, rest string) {
+	trimmed := strings.TrimSpace(command)
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", trimmed
+	}
+	end := strings.Index(trimmed, "]")
+	if end < 0 {
+		return "", trimmed
+	}
+	return strings.TrimSpace(trimmed[1:end]), strings.TrimSpace(trimmed[end+1:])
+}
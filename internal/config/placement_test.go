@@ -0,0 +1,79 @@
+package config
+
+import "testing"
+
+func TestPlacementRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement Placement
+		rules     string
+		display   string
+	}{
+		{"none", Placement{}, "", ""},
+		{"workspace only", Placement{Workspace: "2"}, "workspace 2", "workspace 2"},
+		{"silent workspace", Placement{Workspace: "special:music", Silent: true}, "workspace special:music silent", "workspace special:music silent"},
+		{"monitor only", Placement{Monitor: "DP-1"}, "monitor DP-1", "monitor DP-1"},
+		{"floating without a workspace", Placement{Floating: true}, "float", "float"},
+		{"silent without a workspace", Placement{Silent: true, Floating: true}, "float", "float"},
+		{"everything", Placement{Workspace: "3", Silent: true, Monitor: "HDMI-A-1", Floating: true}, "workspace 3 silent; monitor HDMI-A-1; float", "workspace 3 silent, monitor HDMI-A-1, float"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.placement.Rules(); got != tt.rules {
+				t.Errorf("Rules = %q, want %q", got, tt.rules)
+			}
+			if got := tt.placement.String(); got != tt.display {
+				t.Errorf("String = %q, want %q", got, tt.display)
+			}
+		})
+	}
+}
+
+func TestPlacementApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement Placement
+		command   string
+		want      string
+	}{
+		{"zero placement leaves the command", Placement{}, "[workspace 1] firefox", "[workspace 1] firefox"},
+		{"workspace only", Placement{Workspace: "2"}, "firefox", "[workspace 2] firefox"},
+		{"monitor only", Placement{Monitor: "DP-1"}, "uwsm app -- firefox", "[monitor DP-1] uwsm app -- firefox"},
+		{"floating without a workspace", Placement{Floating: true}, "pavucontrol", "[float] pavucontrol"},
+		{"replaces an existing rule block", Placement{Workspace: "4", Silent: true}, "[workspace 2; float] spotify", "[workspace 4 silent] spotify"},
+		{"replaces an indented rule block", Placement{Floating: true}, "  [monitor DP-2]   foot  ", "[float] foot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.placement.Apply(tt.command); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitExecRules(t *testing.T) {
+	tests := []struct {
+		command string
+		rules   string
+		rest    string
+	}{
+		{"firefox", "", "firefox"},
+		{"[workspace 2 silent] firefox", "workspace 2 silent", "firefox"},
+		{"[workspace 2; float]firefox --new-window", "workspace 2; float", "firefox --new-window"},
+		{" [ monitor DP-1 ] foot ", "monitor DP-1", "foot"},
+		{"[]  foot", "", "foot"},
+		// An unterminated block isn't a rule block
+		{"[workspace 2 firefox", "", "[workspace 2 firefox"},
+		// Brackets later in the command are arguments
+		{"notify-send '[done]'", "", "notify-send '[done]'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			rules, rest := SplitExecRules(tt.command)
+			if rules != tt.rules || rest != tt.rest {
+				t.Errorf("SplitExecRules = %q, %q, want %q, %q", rules, rest, tt.rules, tt.rest)
+			}
+		})
+	}
+}
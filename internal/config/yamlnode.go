@@ -0,0 +1,465 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultYAMLIndent matches the indentation yaml.Marshal produces for new files
+const defaultYAMLIndent = 4
+
+// scalarEdit records an in-place change of a scalar's value
+type scalarEdit struct {
+	node     *yaml.Node
+	oldValue string
+}
+
+// keyInsertion records key/value pairs appended to an existing block mapping
+type keyInsertion struct {
+	mapping *yaml.Node
+	pairs   []*yaml.Node
+}
+
+// yamlMerge tracks the changes made while merging new values into an existing document
+type yamlMerge struct {
+	root       *yaml.Node
+	edits      []scalarEdit   // scalars whose value changed in place
+	insertions []keyInsertion // keys added at the end of block mappings
+	structural bool           // other keys/items were added, removed or reordered
+}
+
+// marshalPreserving renders config on top of the existing YAML document in original,
+// keeping comments, key order, anchors and fields unknown to OmarchyConfig.
+// If only scalar values changed, the original text is patched in place so the
+// diff is limited to those scalars; otherwise the merged document is re-encoded.
+func marshalPreserving(original []byte, config *OmarchyConfig) ([]byte, error) {
+	var doc yaml.Node
+	if len(bytes.TrimSpace(original)) == 0 || yaml.Unmarshal(original, &doc) != nil ||
+		doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Nothing worth preserving
+		return encodeYAML(config, defaultYAMLIndent)
+	}
+
+	var updated yaml.Node
+	if err := updated.Encode(config); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	m := &yamlMerge{root: doc.Content[0]}
+	m.mergeNode(doc.Content[0], &updated, reflect.TypeOf(*config), false)
+
+	if !m.structural {
+		if patched, ok := m.patch(original, detectYAMLIndent(original)); ok {
+			return patched, nil
+		}
+	}
+
+	return encodeYAML(&doc, detectYAMLIndent(original))
+}
+
+// encodeYAML encodes v with the given indentation
+func encodeYAML(v interface{}, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// detectYAMLIndent returns the indentation of the first indented content line
+func detectYAMLIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent := len(line) - len(trimmed); indent > 0 {
+			return indent
+		}
+	}
+	return defaultYAMLIndent
+}
+
+// nodesEqual reports whether two nodes decode to the same value
+// Aliases and merge keys are resolved, so styles and anchors don't matter.
+func nodesEqual(a, b *yaml.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// mergeNode updates old in place so it decodes to the same value as updated
+// t is the Go type updated was encoded from (nil when unknown).
+func (m *yamlMerge) mergeNode(old, updated *yaml.Node, t reflect.Type, inFlow bool) {
+	if nodesEqual(old, updated) {
+		return
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if old.Kind != updated.Kind || old.Kind == yaml.AliasNode {
+		m.replace(old, updated)
+		return
+	}
+
+	inFlow = inFlow || old.Style&yaml.FlowStyle != 0
+
+	switch old.Kind {
+	case yaml.ScalarNode:
+		if inFlow {
+			m.structural = true
+		} else {
+			m.edits = append(m.edits, scalarEdit{node: old, oldValue: old.Value})
+		}
+		old.Value = updated.Value
+		old.Tag = updated.Tag
+	case yaml.MappingNode:
+		m.mergeMapping(old, updated, t, inFlow)
+	case yaml.SequenceNode:
+		m.mergeSequence(old, updated, t, inFlow)
+	default:
+		m.replace(old, updated)
+	}
+}
+
+// replace swaps the content of old for updated, keeping old's comments
+func (m *yamlMerge) replace(old, updated *yaml.Node) {
+	head, line, foot := old.HeadComment, old.LineComment, old.FootComment
+	*old = *updated
+	old.HeadComment, old.LineComment, old.FootComment = head, line, foot
+	m.structural = true
+}
+
+// mergeMapping merges the keys of updated into old
+// Keys missing from updated are removed only if they are known fields of t (or
+// entries of a map), so fields the structs don't know about survive.
+func (m *yamlMerge) mergeMapping(old, updated *yaml.Node, t reflect.Type, inFlow bool) {
+	known := yamlFieldTypes(t)
+
+	// Values reachable through merge keys (<<) count as present
+	var resolved map[string]interface{}
+	_ = old.Decode(&resolved)
+
+	present := make(map[string]bool)
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key, value := updated.Content[i], updated.Content[i+1]
+		present[key.Value] = true
+
+		if j := mappingIndex(old, key.Value); j >= 0 {
+			m.mergeNode(old.Content[j+1], value, fieldType(t, known, key.Value), inFlow)
+			continue
+		}
+
+		var decoded interface{}
+		if v, ok := resolved[key.Value]; ok && value.Decode(&decoded) == nil && reflect.DeepEqual(v, decoded) {
+			continue
+		}
+
+		if old.Style&yaml.FlowStyle != 0 || len(old.Content) == 0 {
+			m.structural = true
+		} else {
+			m.addInsertion(old, key, value)
+		}
+		old.Content = append(old.Content, key, value)
+	}
+
+	isMap := t != nil && t.Kind() == reflect.Map
+	kept := old.Content[:0]
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key := old.Content[i]
+		_, isKnown := known[key.Value]
+		if !present[key.Value] && key.Value != "<<" && (isMap || isKnown) {
+			m.structural = true
+			continue
+		}
+		kept = append(kept, old.Content[i], old.Content[i+1])
+	}
+	old.Content = kept
+}
+
+// addInsertion records a key/value pair appended to mapping
+func (m *yamlMerge) addInsertion(mapping, key, value *yaml.Node) {
+	for i := range m.insertions {
+		if m.insertions[i].mapping == mapping {
+			m.insertions[i].pairs = append(m.insertions[i].pairs, key, value)
+			return
+		}
+	}
+	m.insertions = append(m.insertions, keyInsertion{mapping: mapping, pairs: []*yaml.Node{key, value}})
+}
+
+// mergeSequence merges updated items into old, matching structured items by
+// their "id" or "name" field so reordering and removals keep item comments
+func (m *yamlMerge) mergeSequence(old, updated *yaml.Node, t reflect.Type, inFlow bool) {
+	var elem reflect.Type
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elem = t.Elem()
+	}
+
+	used := make([]bool, len(old.Content))
+	content := make([]*yaml.Node, 0, len(updated.Content))
+	for i, item := range updated.Content {
+		j := matchSequenceItem(old, item, used, i)
+		if j < 0 {
+			content = append(content, item)
+			m.structural = true
+			continue
+		}
+		used[j] = true
+		if j != i {
+			m.structural = true
+		}
+		m.mergeNode(old.Content[j], item, elem, inFlow)
+		content = append(content, old.Content[j])
+	}
+
+	if len(content) != len(old.Content) {
+		m.structural = true
+	}
+	old.Content = content
+}
+
+// matchSequenceItem finds the unused old item corresponding to item
+func matchSequenceItem(old, item *yaml.Node, used []bool, index int) int {
+	if item.Kind == yaml.MappingNode {
+		for _, idKey := range []string{"id", "name"} {
+			k := mappingIndex(item, idKey)
+			if k < 0 {
+				continue
+			}
+			id := item.Content[k+1].Value
+			for j, candidate := range old.Content {
+				if used[j] || candidate.Kind != yaml.MappingNode {
+					continue
+				}
+				if c := mappingIndex(candidate, idKey); c >= 0 && candidate.Content[c+1].Value == id {
+					return j
+				}
+			}
+			return -1
+		}
+	}
+
+	// Unstructured items are matched by position
+	if index < len(old.Content) && !used[index] && old.Content[index].Kind == item.Kind {
+		return index
+	}
+	return -1
+}
+
+// mappingIndex returns the index of key in a mapping node's content, or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlFieldTypes returns the yaml key -> field type of a struct type
+func yamlFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	if t == nil || t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// fieldType returns the Go type stored under key in a struct or map type
+func fieldType(t reflect.Type, known map[string]reflect.Type, key string) reflect.Type {
+	if t != nil && t.Kind() == reflect.Map {
+		return t.Elem()
+	}
+	return known[key]
+}
+
+// patch applies the recorded scalar edits and key insertions directly to the
+// original text. It gives up (ok=false) on anything it can't locate exactly,
+// e.g. multi-line scalars.
+func (m *yamlMerge) patch(original []byte, indent int) ([]byte, bool) {
+	lines := strings.Split(strings.TrimSuffix(string(original), "\n"), "\n")
+
+	// Patch from the end so earlier positions stay valid
+	edits := m.edits
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].node.Line != edits[j].node.Line {
+			return edits[i].node.Line > edits[j].node.Line
+		}
+		return edits[i].node.Column > edits[j].node.Column
+	})
+
+	for _, edit := range edits {
+		node := edit.node
+		if node.Line < 1 || node.Line > len(lines) {
+			return nil, false
+		}
+		line := []rune(lines[node.Line-1])
+		start := node.Column - 1
+		if start < 0 || start >= len(line) {
+			return nil, false
+		}
+
+		end, ok := scalarEnd(line, start, node.Style)
+		if !ok {
+			return nil, false
+		}
+
+		// Make sure the span really is the whole old scalar
+		var spanValue string
+		if err := yaml.Unmarshal([]byte(string(line[start:end])), &spanValue); err != nil || spanValue != edit.oldValue {
+			return nil, false
+		}
+
+		rendered, ok := renderScalar(node)
+		if !ok {
+			return nil, false
+		}
+
+		lines[node.Line-1] = string(line[:start]) + rendered + string(line[end:])
+	}
+
+	// Insert new keys after the last line of their mapping, bottom-most first
+	type textInsertion struct {
+		after int
+		lines []string
+	}
+	var inserts []textInsertion
+	for _, ins := range m.insertions {
+		after := len(lines)
+		if ins.mapping != m.root {
+			last, ok := lastLine(ins.mapping)
+			if !ok {
+				return nil, false
+			}
+			after = last
+		}
+
+		encoded, err := encodeYAML(&yaml.Node{Kind: yaml.MappingNode, Content: ins.pairs}, indent)
+		if err != nil {
+			return nil, false
+		}
+		prefix := strings.Repeat(" ", ins.mapping.Content[0].Column-1)
+		var newLines []string
+		for _, l := range strings.Split(strings.TrimSuffix(string(encoded), "\n"), "\n") {
+			newLines = append(newLines, prefix+l)
+		}
+		inserts = append(inserts, textInsertion{after: after, lines: newLines})
+	}
+	sort.SliceStable(inserts, func(i, j int) bool { return inserts[i].after > inserts[j].after })
+	for _, ins := range inserts {
+		rest := append(append([]string{}, ins.lines...), lines[ins.after:]...)
+		lines = append(lines[:ins.after], rest...)
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), true
+}
+
+// lastLine returns the last source line used by node's original content
+// Multi-line scalars make the end unknown, so they report ok=false.
+func lastLine(node *yaml.Node) (int, bool) {
+	last := node.Line
+	for _, child := range node.Content {
+		if child.Line == 0 {
+			// Added by the merge, not part of the original text
+			continue
+		}
+		if child.Kind == yaml.ScalarNode && (child.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(child.Value, "\n")) {
+			return 0, false
+		}
+		l, ok := lastLine(child)
+		if !ok {
+			return 0, false
+		}
+		if l > last {
+			last = l
+		}
+	}
+	return last, true
+}
+
+// scalarEnd returns the end (exclusive) of the single-line scalar starting at start
+func scalarEnd(line []rune, start int, style yaml.Style) (int, bool) {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] == '"' {
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return 0, false
+	}
+
+	// Plain scalar: runs until a comment or the end of the line
+	end := len(line)
+	for i := start; i < len(line); i++ {
+		if line[i] == '#' && i > start && (line[i-1] == ' ' || line[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+	for end > start && (line[end-1] == ' ' || line[end-1] == '\t') {
+		end--
+	}
+	if end == len(line) && end == start {
+		return 0, false
+	}
+	return end, true
+}
+
+// renderScalar encodes a scalar node on a single line, keeping its style where valid
+func renderScalar(node *yaml.Node) (string, bool) {
+	scalar := &yaml.Node{Kind: yaml.ScalarNode, Value: node.Value, Tag: node.Tag, Style: node.Style}
+	data, err := yaml.Marshal(scalar)
+	if err != nil {
+		return "", false
+	}
+	rendered := strings.TrimSuffix(string(data), "\n")
+	if strings.Contains(rendered, "\n") {
+		return "", false
+	}
+	return rendered, true
+}
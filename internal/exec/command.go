@@ -0,0 +1,109 @@
+package exec
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"strings"
+)
+
+// ExpandFieldCodes replaces the desktop entry field codes in args.
+// %f/%F/%u/%U expand to the given files or URLs (none when launched from the TUI),
+// %i to "--icon <Icon>", %c to the application name and %k to the desktop file path.
+// Deprecated codes (%d, %D, %n, %N, %v, %m) are removed and %% becomes a literal %.
+func ExpandFieldCodes(args []string, app *config.Application, files []string) []string {
+	expanded := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		// Field codes that expand to multiple arguments must stand alone
+		switch arg {
+		case "%F", "%U":
+			expanded = append(expanded, files...)
+			continue
+		case "%i":
+			if app.Icon != "" {
+				expanded = append(expanded, "--icon", app.Icon)
+			}
+			continue
+		}
+
+		var b strings.Builder
+		dropped := false
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 >= len(arg) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			i++
+			switch arg[i] {
+			case '%':
+				b.WriteByte('%')
+			case 'f', 'u':
+				if len(files) > 0 {
+					b.WriteString(files[0])
+				} else if arg == "%f" || arg == "%u" {
+					dropped = true
+				}
+			case 'c':
+				b.WriteString(app.Name)
+			case 'k':
+				b.WriteString(app.DesktopFile)
+			case 'F', 'U', 'i', 'd', 'D', 'n', 'N', 'v', 'm':
+				// Invalid inside a larger argument or deprecated - remove
+			default:
+				// Unknown field code, keep it untouched
+				b.WriteByte('%')
+				b.WriteByte(arg[i])
+			}
+		}
+
+		if dropped {
+			continue
+		}
+		expanded = append(expanded, b.String())
+	}
+
+	return expanded
+}
+
+// BuildCommand returns the argv used to launch app
+// It prefers the full Exec line and falls back to the bare package name
+func BuildCommand(app *config.Application) ([]string, error) {
+	if app.Exec == "" {
+		if app.PackageName == "" {
+			return nil, fmt.Errorf("application '%s' has no Exec line or package name", app.Name)
+		}
+		return []string{app.PackageName}, nil
+	}
+
+	args, err := config.SplitExec(app.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	args = ExpandFieldCodes(args, app, nil)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Exec line for '%s' expands to nothing", app.Name)
+	}
+	return args, nil
+}
+
+// JoinCommand joins an argv into a single shell command line, quoting arguments
+// that contain shell metacharacters. Used for commands written into Hyprland configs.
+func JoinCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes arg for POSIX sh if needed
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(arg, " \t\n'\"\\$`!*?[](){}<>|&;#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,47 @@
+package exec
+
+import (
+	"omarchy-tui/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestExpandFieldCodes(t *testing.T) {
+	app := &config.Application{
+		Name:        "My App",
+		Icon:        "myapp",
+		DesktopFile: "/usr/share/applications/myapp.desktop",
+	}
+	noIcon := &config.Application{Name: "Bare"}
+
+	tests := []struct {
+		name  string
+		args  []string
+		app   *config.Application
+		files []string
+		want  []string
+	}{
+		{"no files drops %u", []string{"app", "%u"}, app, nil, []string{"app"}},
+		{"no files drops %F", []string{"app", "%F"}, app, nil, []string{"app"}},
+		{"single file", []string{"app", "%f"}, app, []string{"a.txt", "b.txt"}, []string{"app", "a.txt"}},
+		{"file list", []string{"app", "%U"}, app, []string{"a", "b"}, []string{"app", "a", "b"}},
+		{"embedded file", []string{"app", "--open=%u"}, app, []string{"x"}, []string{"app", "--open=x"}},
+		{"embedded without file", []string{"app", "--open=%u"}, app, nil, []string{"app", "--open="}},
+		{"icon", []string{"app", "%i"}, app, nil, []string{"app", "--icon", "myapp"}},
+		{"icon without icon", []string{"app", "%i"}, noIcon, nil, []string{"app"}},
+		{"name and desktop file", []string{"app", "%c", "%k"}, app, nil, []string{"app", "My App", "/usr/share/applications/myapp.desktop"}},
+		{"literal percent", []string{"app", "100%%"}, app, nil, []string{"app", "100%"}},
+		{"deprecated codes", []string{"app", "%d%D%n%N%v%m"}, app, nil, []string{"app", ""}},
+		{"unknown code kept", []string{"app", "%z"}, app, nil, []string{"app", "%z"}},
+		{"trailing percent", []string{"app", "50%"}, app, nil, []string{"app", "50%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpandFieldCodes(tt.args, tt.app, tt.files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandFieldCodes(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
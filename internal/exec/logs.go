@@ -0,0 +1,109 @@
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"omarchy-tui/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stateDir returns the omarchy-tui directory under XDG_STATE_HOME
+func stateDir() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "omarchy-tui"), nil
+}
+
+// LogPath returns the output log file for app, creating its directory if needed
+// Logs live in $XDG_STATE_HOME/omarchy-tui/logs/<app>.log
+func LogPath(app *config.Application) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	return filepath.Join(dir, logFileName(app)), nil
+}
+
+// logFileName turns an application name into a safe file name
+func logFileName(app *config.Application) string {
+	name := app.Name
+	if name == "" {
+		name = app.PackageName
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "app.log"
+	}
+	return b.String() + ".log"
+}
+
+// openLaunchLog opens the log at path for appending and marks the start of a new launch
+// The log is never truncated: an earlier instance of the app may still be writing to it.
+// The returned offset is where the new launch's output begins.
+func openLaunchLog(path string, args []string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	header := fmt.Sprintf("=== %s launching %s\n", time.Now().Format(time.RFC3339), JoinCommand(args))
+	if _, err := file.WriteString(header); err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to write log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to stat log file: %w", err)
+	}
+	return file, info.Size(), nil
+}
+
+// TailLog returns the last n lines of the log file at path
+func TailLog(path string, n int) ([]string, error) {
+	return tailLogFrom(path, 0, n)
+}
+
+// tailLogFrom returns the last n lines of the log file at path written after offset
+func tailLogFrom(path string, offset int64, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -1,31 +1,177 @@
 package exec
 
 import (
+	"errors"
 	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hypr"
+	"omarchy-tui/internal/logger"
 	"os"
 	"os/exec"
+	"syscall"
+	"time"
 )
 
-// LaunchApp launches an application by package name
-func LaunchApp(packageName string) error {
-	executable, err := FindExecutable(packageName)
+// launchGracePeriod is how long after start an exit with an error counts as a
+// failed launch
+const launchGracePeriod = 500 * time.Millisecond
+
+// launchLogTailLines is the number of log lines attached to a LaunchError
+const launchLogTailLines = 10
+
+// LaunchOptions carries launch settings that come from outside the application itself
+type LaunchOptions struct {
+	// Terminal is the terminal emulator used for Terminal=true apps (the
+	// category default); nil falls back to $TERMINAL or xdg-terminal-exec
+	Terminal *config.Application
+	// OnEarlyExit is called from a background goroutine when the app exits with an
+	// error within launchGracePeriod; nil ignores early exits
+	OnEarlyExit func(err *LaunchError)
+}
+
+// LaunchError describes a failed launch, including the tail of the app's output log
+type LaunchError struct {
+	App     string
+	Err     error
+	LogPath string
+	LogTail []string
+}
+
+func (e *LaunchError) Error() string {
+	return fmt.Sprintf("failed to launch %s: %v", e.App, e.Err)
+}
+
+func (e *LaunchError) Unwrap() error {
+	return e.Err
+}
+
+// LaunchApp launches an application from its desktop Exec line
+// Apps without an Exec line are launched by package name.
+// The process runs in its own session with stdin from /dev/null and its
+// output appended to a per-app log file (see LogPath). LaunchApp returns once the
+// process has started; it is reaped in the background, and an exit with an error
+// shortly after start is reported to opts.OnEarlyExit.
+// Apps with a Placement are started through Hyprland's exec dispatcher when Hyprland
+// is running, and fall back to a plain exec (without placement) otherwise.
+// A SingleInstance app that already has a window is focused instead of launched.
+func LaunchApp(app *config.Application, opts LaunchOptions) error {
+	var ipc *hypr.IPC
+	if app.SingleInstance || !app.Placement.IsZero() {
+		var err error
+		if ipc, err = hypr.NewIPC(); err != nil {
+			logger.Log("LaunchApp: %v, launching %s without placement or focus", err, app.Name)
+		}
+	}
+
+	if app.SingleInstance && ipc != nil {
+		focused, err := ipc.FocusApp(app)
+		if err != nil {
+			logger.Log("LaunchApp: Failed to look for a window of %s: %v", app.Name, err)
+		} else if focused {
+			return nil
+		}
+	}
+
+	args, err := ResolveCommand(app, opts)
+	if err != nil {
+		return &LaunchError{App: app.Name, Err: err}
+	}
+
+	executable, err := FindExecutable(args[0])
+	if err != nil {
+		return &LaunchError{App: app.Name, Err: err}
+	}
+
+	logPath, err := LogPath(app)
+	if err != nil {
+		return &LaunchError{App: app.Name, Err: err}
+	}
+
+	if !app.Placement.IsZero() && ipc != nil {
+		return dispatchLaunch(ipc, app, args, logPath)
+	}
+
+	logFile, logOffset, err := openLaunchLog(logPath, args)
+	if err != nil {
+		return &LaunchError{App: app.Name, Err: err}
+	}
+	defer logFile.Close() // the child keeps its own descriptor
+
+	devNull, err := os.Open(os.DevNull)
 	if err != nil {
-		return err
+		return &LaunchError{App: app.Name, Err: err}
 	}
+	defer devNull.Close()
 
-	cmd := exec.Command(executable)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := exec.Command(executable, args[1:]...)
+	cmd.Stdin = devNull
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// New session: no controlling terminal and not part of our process group
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 
 	// Start the process in the background (detached)
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start application: %w", err)
+		return &LaunchError{App: app.Name, Err: fmt.Errorf("failed to start application: %w", err), LogPath: logPath}
 	}
 
-	// Don't wait for the process to complete
+	// Reap the process whenever it exits so it never becomes a zombie, without
+	// holding up the caller (the TUI event loop)
+	started := time.Now()
+	go func() {
+		err := cmd.Wait()
+		if err == nil || opts.OnEarlyExit == nil || time.Since(started) > launchGracePeriod {
+			return
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = fmt.Errorf("application exited immediately: %s", exitErr.ProcessState)
+		}
+		tail, _ := tailLogFrom(logPath, logOffset, launchLogTailLines)
+		opts.OnEarlyExit(&LaunchError{App: app.Name, Err: err, LogPath: logPath, LogTail: tail})
+	}()
+
 	return nil
 }
 
+// dispatchLaunch starts args with Hyprland's exec dispatcher, prefixed with the app's
+// placement rules ("[workspace 2 silent] firefox")
+// Hyprland runs the command through /bin/sh, so the output is appended to the log
+// there; an early exit can't be detected this way.
+func dispatchLaunch(ipc *hypr.IPC, app *config.Application, args []string, logPath string) error {
+	logFile, _, err := openLaunchLog(logPath, args)
+	if err != nil {
+		return &LaunchError{App: app.Name, Err: err}
+	}
+	logFile.Close()
+
+	command := fmt.Sprintf("%s >>%s 2>&1 </dev/null", JoinCommand(args), shellQuote(logPath))
+	if err := ipc.Dispatch("exec", app.Placement.Apply(command)); err != nil {
+		return &LaunchError{App: app.Name, Err: err, LogPath: logPath}
+	}
+	logger.Log("dispatchLaunch: Launched %s with [%s]", app.Name, app.Placement.Rules())
+	return nil
+}
+
+// ResolveCommand returns the final argv for app, wrapping it in a terminal
+// emulator when the app sets Terminal=true
+func ResolveCommand(app *config.Application, opts LaunchOptions) ([]string, error) {
+	args, err := BuildCommand(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if !app.Terminal {
+		return args, nil
+	}
+
+	terminal, err := resolveTerminal(opts.Terminal)
+	if err != nil {
+		return nil, err
+	}
+	return WrapInTerminal(terminal, app, args), nil
+}
+
 // FindExecutable resolves a package name to an executable path
 func FindExecutable(packageName string) (string, error) {
 	executable, err := exec.LookPath(packageName)
@@ -40,4 +186,3 @@ func IsExecutableAvailable(packageName string) bool {
 	_, err := exec.LookPath(packageName)
 	return err == nil
 }
-
@@ -0,0 +1,124 @@
+package exec
+
+import (
+	"errors"
+	"net"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hypr"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLaunchAppReportsEarlyExit(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+
+	app := &config.Application{Name: "Failing", PackageName: "sh", Exec: `sh -c "echo boom; exit 3"`}
+	logPath, err := LogPath(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logPath, []byte("output of a running instance\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exited := make(chan *LaunchError, 1)
+	start := time.Now()
+	if err := LaunchApp(app, LaunchOptions{OnEarlyExit: func(err *LaunchError) { exited <- err }}); err != nil {
+		t.Fatalf("LaunchApp: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= launchGracePeriod {
+		t.Errorf("LaunchApp blocked for %v", elapsed)
+	}
+
+	select {
+	case launchErr := <-exited:
+		if !reflect.DeepEqual(launchErr.LogTail, []string{"boom"}) {
+			t.Errorf("LogTail = %q, want only this launch's output", launchErr.LogTail)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("early exit was not reported")
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "output of a running instance\n") {
+		t.Errorf("log was truncated: %q", data)
+	}
+}
+
+// stubHyprland answers every request on a Hyprland-style socket with reply and sends
+// the requests it gets on the returned channel
+func stubHyprland(t *testing.T, reply string) (*hypr.IPC, <-chan string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".socket.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	requests := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 8192)
+			n, _ := conn.Read(buf)
+			requests <- string(buf[:n])
+			conn.Write([]byte(reply))
+			conn.Close()
+		}
+	}()
+	return &hypr.IPC{SocketPath: path, Timeout: time.Second}, requests
+}
+
+func TestDispatchLaunch(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "it's.log")
+	redirect := ` >>'` + strings.ReplaceAll(logPath, "'", `'\''`) + `' 2>&1 </dev/null`
+
+	tests := []struct {
+		name      string
+		placement config.Placement
+		args      []string
+		want      string
+	}{
+		{"no placement", config.Placement{}, []string{"firefox"}, "dispatch exec firefox" + redirect},
+		{"workspace only", config.Placement{Workspace: "2", Silent: true}, []string{"firefox"}, "dispatch exec [workspace 2 silent] firefox" + redirect},
+		{"monitor only", config.Placement{Monitor: "DP-1"}, []string{"firefox", "--new-window"}, "dispatch exec [monitor DP-1] firefox --new-window" + redirect},
+		{"floating without a workspace", config.Placement{Floating: true}, []string{"pavucontrol"}, "dispatch exec [float] pavucontrol" + redirect},
+		{"quoted arguments", config.Placement{Workspace: "3"}, []string{"omarchy-launch-webapp", "https://example.com/?a=1&b=2"}, "dispatch exec [workspace 3] omarchy-launch-webapp 'https://example.com/?a=1&b=2'" + redirect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipc, requests := stubHyprland(t, "ok")
+			app := &config.Application{Name: "Test", Placement: tt.placement}
+			if err := dispatchLaunch(ipc, app, tt.args, logPath); err != nil {
+				t.Fatalf("dispatchLaunch: %v", err)
+			}
+			if got := <-requests; got != tt.want {
+				t.Errorf("request = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchLaunchRefused(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+	ipc, _ := stubHyprland(t, "Invalid dispatcher")
+	app := &config.Application{Name: "Test", Placement: config.Placement{Workspace: "2"}}
+
+	err := dispatchLaunch(ipc, app, []string{"firefox"}, logPath)
+	var launchErr *LaunchError
+	if !errors.As(err, &launchErr) || launchErr.LogPath != logPath || !strings.Contains(err.Error(), "Invalid dispatcher") {
+		t.Errorf("dispatchLaunch error = %#v, want a LaunchError with Hyprland's reply and the log path", err)
+	}
+}
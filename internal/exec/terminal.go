@@ -0,0 +1,76 @@
+package exec
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// terminalTemplates holds the arguments appended to a terminal command to run a
+// program in it. "{title}" is replaced by the app name and "{cmd}" by the program argv.
+var terminalTemplates = map[string][]string{
+	"alacritty":         {"--title", "{title}", "-e", "{cmd}"},
+	"kitty":             {"--title", "{title}", "{cmd}"},
+	"foot":              {"--title", "{title}", "{cmd}"},
+	"ghostty":           {"--title={title}", "-e", "{cmd}"},
+	"xdg-terminal-exec": {"{cmd}"},
+}
+
+// defaultTerminalTemplate is used for terminals without a specific template
+var defaultTerminalTemplate = []string{"-e", "{cmd}"}
+
+// IsKnownTerminal reports whether argv invokes a terminal we have a template for
+func IsKnownTerminal(argv []string) bool {
+	_, ok := findTerminalTemplate(argv)
+	return ok
+}
+
+// findTerminalTemplate looks through argv for a known terminal binary
+// Wrappers like "uwsm app -- alacritty" are handled by checking every element.
+func findTerminalTemplate(argv []string) ([]string, bool) {
+	for _, arg := range argv {
+		if template, ok := terminalTemplates[filepath.Base(arg)]; ok {
+			return template, true
+		}
+	}
+	return defaultTerminalTemplate, false
+}
+
+// resolveTerminal returns the argv of the terminal used for Terminal=true apps
+// Order: the given terminal app (the category default), $TERMINAL, xdg-terminal-exec
+func resolveTerminal(terminal *config.Application) ([]string, error) {
+	if terminal != nil {
+		if argv, err := BuildCommand(terminal); err == nil {
+			return argv, nil
+		}
+	}
+
+	if env := strings.TrimSpace(os.Getenv("TERMINAL")); env != "" {
+		if argv, err := config.SplitExec(env); err == nil {
+			return argv, nil
+		}
+	}
+
+	if IsExecutableAvailable("xdg-terminal-exec") {
+		return []string{"xdg-terminal-exec"}, nil
+	}
+
+	return nil, fmt.Errorf("no terminal emulator found: set a default terminal or $TERMINAL")
+}
+
+// WrapInTerminal returns the argv that runs args inside terminal for app
+func WrapInTerminal(terminal []string, app *config.Application, args []string) []string {
+	template, _ := findTerminalTemplate(terminal)
+
+	wrapped := append([]string{}, terminal...)
+	for _, part := range template {
+		if part == "{cmd}" {
+			wrapped = append(wrapped, args...)
+			continue
+		}
+		wrapped = append(wrapped, strings.ReplaceAll(part, "{title}", app.Name))
+	}
+	return wrapped
+}
@@ -0,0 +1,100 @@
+package exec
+
+import (
+	"omarchy-tui/internal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWrapInTerminal(t *testing.T) {
+	app := &config.Application{Name: "btop"}
+	args := []string{"btop", "--utf-force"}
+
+	tests := []struct {
+		name     string
+		terminal []string
+		want     []string
+	}{
+		{"alacritty -e", []string{"alacritty"}, []string{"alacritty", "--title", "btop", "-e", "btop", "--utf-force"}},
+		{"kitty bare", []string{"kitty"}, []string{"kitty", "--title", "btop", "btop", "--utf-force"}},
+		{"foot bare", []string{"/usr/bin/foot"}, []string{"/usr/bin/foot", "--title", "btop", "btop", "--utf-force"}},
+		{"ghostty --title=", []string{"ghostty"}, []string{"ghostty", "--title=btop", "-e", "btop", "--utf-force"}},
+		{"xdg-terminal-exec", []string{"xdg-terminal-exec"}, []string{"xdg-terminal-exec", "btop", "--utf-force"}},
+		{
+			"behind a launcher wrapper",
+			[]string{"uwsm", "app", "--", "alacritty"},
+			[]string{"uwsm", "app", "--", "alacritty", "--title", "btop", "-e", "btop", "--utf-force"},
+		},
+		{"terminal options kept", []string{"kitty", "--single-instance"}, []string{"kitty", "--single-instance", "--title", "btop", "btop", "--utf-force"}},
+		{"unknown terminal falls back to -e", []string{"st"}, []string{"st", "-e", "btop", "--utf-force"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminal := append([]string{}, tt.terminal...)
+			if got := WrapInTerminal(terminal, app, args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapInTerminal(%q) = %q, want %q", tt.terminal, got, tt.want)
+			}
+			if !reflect.DeepEqual(terminal, tt.terminal) {
+				t.Errorf("WrapInTerminal modified the terminal argv: %q", terminal)
+			}
+		})
+	}
+}
+
+func TestIsKnownTerminal(t *testing.T) {
+	for argv, want := range map[string]bool{
+		"alacritty": true,
+		"ghostty":   true,
+		"st":        false,
+		"":          false,
+	} {
+		if got := IsKnownTerminal([]string{argv}); got != want {
+			t.Errorf("IsKnownTerminal(%q) = %v, want %v", argv, got, want)
+		}
+	}
+}
+
+func TestResolveTerminal(t *testing.T) {
+	pathDir := t.TempDir()
+	t.Setenv("PATH", pathDir)
+
+	tests := []struct {
+		name        string
+		terminal    *config.Application
+		env         string
+		xdgTerminal bool
+		want        []string
+		wantErr     bool
+	}{
+		{"category default", &config.Application{Name: "Alacritty", Exec: "alacritty --class Term"}, "kitty", true, []string{"alacritty", "--class", "Term"}, false},
+		{"default without Exec", &config.Application{Name: "Foot", PackageName: "foot"}, "", false, []string{"foot"}, false},
+		{"$TERMINAL", nil, "kitty --single-instance", true, []string{"kitty", "--single-instance"}, false},
+		{"broken default falls back to $TERMINAL", &config.Application{Name: "Broken"}, "kitty", false, []string{"kitty"}, false},
+		{"xdg-terminal-exec", nil, "", true, []string{"xdg-terminal-exec"}, false},
+		{"none", nil, "  ", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERMINAL", tt.env)
+			xdg := filepath.Join(pathDir, "xdg-terminal-exec")
+			os.Remove(xdg)
+			if tt.xdgTerminal {
+				if err := os.WriteFile(xdg, []byte("#!/bin/sh\n"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := resolveTerminal(tt.terminal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTerminal error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTerminal = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
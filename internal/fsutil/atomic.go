@@ -0,0 +1,118 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to path through a temporary file in the same
+// directory followed by a rename, so readers (and crashes) never observe a
+// partially written file. Symlinks are followed so dotfile managers keep working,
+// and an existing file keeps its permissions.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Clean up the temp file on any failure
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	success = true
+
+	// Persist the rename itself
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// backup holds the content of a file before a transaction changed it
+type backup struct {
+	path    string
+	data    []byte
+	perm    os.FileMode
+	existed bool
+}
+
+// Transaction groups atomic writes to several files so they can be rolled back
+// together if a later step fails
+type Transaction struct {
+	backups []backup
+}
+
+// NewTransaction creates an empty transaction
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// WriteFile atomically writes path, remembering its previous content for Rollback
+func (t *Transaction) WriteFile(path string, data []byte, perm os.FileMode) error {
+	b := backup{path: path, perm: perm}
+	if old, err := os.ReadFile(path); err == nil {
+		b.data = old
+		b.existed = true
+		if info, err := os.Stat(path); err == nil {
+			b.perm = info.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	if err := WriteFileAtomic(path, data, perm); err != nil {
+		return err
+	}
+	t.backups = append(t.backups, b)
+	return nil
+}
+
+// Rollback restores every file written by the transaction, most recent first
+func (t *Transaction) Rollback() error {
+	var firstErr error
+	for i := len(t.backups) - 1; i >= 0; i-- {
+		b := t.backups[i]
+		var err error
+		if b.existed {
+			err = WriteFileAtomic(b.path, b.data, b.perm)
+		} else {
+			err = os.Remove(b.path)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to restore %s: %w", b.path, err)
+		}
+	}
+	t.backups = nil
+	return firstErr
+}
@@ -0,0 +1,88 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// lockTimeout is how long Lock waits for another instance to finish writing
+const lockTimeout = 5 * time.Second
+
+var (
+	lockMu    sync.Mutex // guards lockDepth and lockFile, not the lock itself
+	lockDepth int
+	lockFile  *os.File
+)
+
+// lockPath returns the advisory lock file shared by all omarchy-tui instances
+func lockPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "omarchy-tui.lock")
+}
+
+// Lock takes the advisory lock guarding writes to omarchy.conf.yaml and the
+// Hyprland config files, so two instances can't interleave their edits.
+// The lock is reentrant within the process; call the returned function to release it.
+//
+// Reentrancy is tracked with a process-wide depth counter, not per goroutine, so
+// the lock does not exclude goroutines of the same process from each other: only
+// one goroutine may take it. In the TUI that is the event loop goroutine; work done
+// elsewhere (e.g. a launch watcher) must hand its config writes back to the event
+// loop with QueueUpdate instead of calling Lock.
+func Lock() (func(), error) {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	if lockDepth > 0 {
+		lockDepth++
+		return unlock, nil
+	}
+
+	file, err := os.OpenFile(lockPath(), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK || time.Now().After(deadline) {
+			file.Close()
+			if err == syscall.EWOULDBLOCK {
+				return nil, fmt.Errorf("another omarchy-tui instance is writing the configuration")
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath(), err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	lockFile = file
+	lockDepth = 1
+	return unlock, nil
+}
+
+// unlock releases one level of the reentrant lock
+func unlock() {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	if lockDepth == 0 {
+		return
+	}
+	lockDepth--
+	if lockDepth == 0 && lockFile != nil {
+		syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		lockFile.Close()
+		lockFile = nil
+	}
+}
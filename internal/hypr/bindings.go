@@ -4,233 +4,491 @@ import (
 	"bufio"
 	"fmt"
 	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/fsutil"
+	"omarchy-tui/internal/hyprconf"
 	"omarchy-tui/internal/logger"
 	"os"
-	"os/user"
-	"path/filepath"
+	"os/exec"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
-// expandPath expands ~ to user home directory
-func expandPath(path string) (string, error) {
-	if strings.HasPrefix(path, "~") {
-		usr, err := user.Current()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(usr.HomeDir, path[1:]), nil
+// readLines reads a config file into lines
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
-	return path, nil
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
 }
 
-// parseBinddLine parses a bindd line and extracts its components
-func parseBinddLine(line string) (modifiers, key, label, command string, err error) {
-	// Remove "bindd ="
-	line = strings.TrimPrefix(line, "bindd =")
-	line = strings.TrimSpace(line)
+// joinLines turns lines back into file content
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
 
-	// Split by comma
-	parts := strings.Split(line, ",")
-	if len(parts) < 5 {
-		return "", "", "", "", fmt.Errorf("invalid bindd line format")
-	}
+// writeLines atomically writes lines back to a config file
+func writeLines(path string, lines []string) error {
+	return fsutil.WriteFileAtomic(path, joinLines(lines), 0644)
+}
 
-	modifiers = strings.TrimSpace(parts[0])
-	key = strings.TrimSpace(parts[1])
-	label = strings.TrimSpace(parts[2])
-	// parts[3] should be "exec"
-	command = strings.TrimSpace(parts[4])
-	// If there are more parts, join them for the command
-	if len(parts) > 5 {
-		command = strings.Join(parts[4:], ",")
-		command = strings.TrimSpace(command)
+// newExecBind creates a described exec bind for a command
+func newExecBind(modifiers, key, label, command string) *hyprconf.Bind {
+	return &hyprconf.Bind{
+		Flags:       "d",
+		Mods:        modifiers,
+		Key:         key,
+		Description: label,
+		Dispatcher:  "exec",
+		Args:        command,
 	}
-
-	return modifiers, key, label, command, nil
 }
 
-// createBinddLine creates a bindd line from components
-func createBinddLine(modifiers, key, label, command string) string {
-	return fmt.Sprintf("bindd = %s, %s, %s, exec, %s", modifiers, key, label, command)
+// execArgs returns the arguments of an exec bind of app: command with the app's placement
+// rules ("[workspace 2 silent] firefox"), and for the bind labeled with the app name of a
+// SingleInstance app, wrapped to focus an open window instead (see FocusOrLaunchCommand).
+// Rules already on command are kept when the app has no placement. The focus-or-launch
+// snippet needs jq, so it fails when jq isn't installed.
+func execArgs(app *config.Application, label, command string) (string, error) {
+	rules, command := config.SplitExecRules(command)
+	if launch, ok := config.SplitFocusOrLaunch(command); ok {
+		command = launch
+	}
+	if app.SingleInstance && strings.EqualFold(label, app.Name) {
+		if _, err := exec.LookPath("jq"); err != nil {
+			return "", fmt.Errorf("single-instance keybindings need jq to find the open window: %w", err)
+		}
+		command = config.FocusOrLaunchCommand(app.WindowClass(), app.WindowTitle, command)
+	}
+	if rules != "" {
+		command = "[" + rules + "] " + command
+	}
+	return app.Placement.Apply(command), nil
 }
 
-// findOriginalBindLine finds the original bindd line for an app by LABEL
-func findOriginalBindLine(lines []string, appName string) (originalLine string, lineIndex int, found bool) {
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		// Skip comments and empty lines
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+// findOriginalBinds finds the active binds in bindings.conf (lines, read from path) that
+// are app's keybinding labelled label, matched like the loader does (see
+// config.Application.MatchesBind). They are located by the File and Line of the parsed
+// config and returned as written, variables unresolved, in file order. Binds in the
+// label's own override block don't count.
+func findOriginalBinds(hyprConfig *hyprconf.Config, lines []string, path string, app *config.Application, label string) []*hyprconf.Bind {
+	if hyprConfig == nil {
+		return nil
+	}
+	if label == "" {
+		label = app.Name
+	}
+
+	var originals []*hyprconf.Bind
+	for _, parsed := range hyprConfig.ActiveBinds() {
+		if !isSameFile(parsed.File, path) || !app.MatchesBind(parsed, label) {
 			continue
 		}
-		// Check if it's a bindd line
-		if strings.HasPrefix(trimmed, "bindd =") {
-			_, _, label, _, err := parseBinddLine(trimmed)
-			if err == nil && strings.EqualFold(label, appName) {
-				return line, i, true
-			}
+		index := parsed.Line - 1
+		if index < 0 || index >= len(lines) || lines[index] != parsed.Raw {
+			logger.Log("findOriginalBinds: %s:%d changed since it was parsed, skipping", parsed.File, parsed.Line)
+			continue
+		}
+		if inOverrideBlock(lines, index, label) {
+			continue
+		}
+		bind, err := hyprconf.ParseBindLine(lines[index])
+		if err != nil {
+			continue
 		}
+		bind.File, bind.Line = parsed.File, parsed.Line
+		originals = append(originals, bind)
 	}
-	return "", -1, false
+	return originals
 }
 
-// updateOmarchyConfig updates the keybinding in omarchy.conf.yaml
-func updateOmarchyConfig(appName, keybinding string) error {
-	configPath, err := expandPath("~/.config/omarchy.conf.yaml")
-	if err != nil {
-		return fmt.Errorf("failed to expand config path: %w", err)
+// isAppBind reports whether bind, as written in bindings.conf, is app's keybinding
+// labelled label; variables are resolved with hyprConfig before matching
+func isAppBind(hyprConfig *hyprconf.Config, app *config.Application, bind *hyprconf.Bind, label string) bool {
+	resolved := *bind
+	if hyprConfig != nil {
+		resolved.Description = hyprConfig.Substitute(bind.Description)
+		resolved.Args = hyprConfig.Substitute(bind.Args)
 	}
+	return app.MatchesBind(&resolved, label)
+}
 
-	// Load config
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+// lastBindInBlock returns the last bind of an override block
+func lastBindInBlock(block []string) *hyprconf.Bind {
+	for i := len(block) - 1; i >= 0; i-- {
+		if bind, err := hyprconf.ParseBindLine(block[i]); err == nil {
+			return bind
+		}
 	}
+	return nil
+}
 
-	// Find and update app
-	found := false
-	for i := range cfg.AppsInventory {
-		if strings.EqualFold(cfg.AppsInventory[i].Name, appName) {
-			cfg.AppsInventory[i].Keybinding = keybinding
-			found = true
-			logger.Log("updateOmarchyConfig: Updated keybinding for app '%s' to '%s'", appName, keybinding)
-			break
+// insertOverride inserts override lines after the "# OVERRIDES" marker,
+// adding the section at the end of the file if it doesn't exist yet
+func insertOverride(lines []string, override ...string) []string {
+	for i, line := range lines {
+		if strings.TrimSpace(line) == overridesSection {
+			logger.Log("insertOverride: Added override after OVERRIDES marker")
+			return insertLines(lines, i+1, override...)
 		}
 	}
 
-	if !found {
+	logger.Log("insertOverride: Added OVERRIDES section at end of file")
+	lines = append(lines, "", overridesSection)
+	return append(lines, override...)
+}
+
+// disableConflicts comments out, on behalf of label, the conflicting binds that live
+// in the file at path; binds in other files are handled by externalUnbinds
+func disableConflicts(lines []string, path, label string, conflicts []Conflict) {
+	for _, conflict := range conflicts {
+		if !isSameFile(conflict.Bind.File, path) {
+			continue
+		}
+		index := conflict.Bind.Line - 1
+		if index < 0 || index >= len(lines) {
+			continue
+		}
+		// Make sure the line is still the bind we were told about
+		bind, err := hyprconf.ParseBindLine(lines[index])
+		if err != nil || bind.Key != conflict.Bind.Key || bind.Dispatcher != conflict.Bind.Dispatcher {
+			logger.Log("disableConflicts: Line %d changed since it was parsed, skipping", conflict.Bind.Line)
+			continue
+		}
+		lines[index] = disableLine(lines[index], label)
+		logger.Log("disableConflicts: Commented out conflicting bind at %s", conflict.Location())
+	}
+}
+
+// appKeybinding describes bind, now on keys, as the app keybinding labelled label
+func appKeybinding(label string, keys config.Keybinding, bind *hyprconf.Bind) config.AppKeybinding {
+	binding := config.AppKeybinding{Keys: keys, Label: label}
+	if bind != nil {
+		binding.Flags = string(bind.Flags)
+		if bind.IsExec() {
+			binding.Command = bind.Args
+		}
+	}
+	return binding
+}
+
+// updateOmarchyConfig updates one of the app's keybindings in omarchy.conf.yaml
+// A binding without keys is removed from the app. Only that binding is written.
+func updateOmarchyConfig(appName string, binding config.AppKeybinding) error {
+	return config.UpdateConfig(func(cfg *config.OmarchyConfig) error {
+		for i := range cfg.AppsInventory {
+			if strings.EqualFold(cfg.AppsInventory[i].Name, appName) {
+				cfg.AppsInventory[i].SetKeybinding(binding)
+				logger.Log("updateOmarchyConfig: Updated keybinding '%s' for app '%s' to '%s'", binding.Label, appName, binding.Keys)
+				return nil
+			}
+		}
 		return fmt.Errorf("app '%s' not found in config", appName)
+	})
+}
+
+// AddKeybinding adds or updates one of app's keybindings in hyprland bindings.conf and
+// omarchy.conf.yaml. label identifies the binding and is its bind description; "" is
+// the app's main binding, labelled with the app name.
+// The keybinding may use any notation config.ParseKeybinding accepts; it is stored in
+// canonical Hyprland form.
+// If bindings.conf already has a bind for the keybinding (matched like the loader does:
+// desktop ID, command, executable, then label), it comments out the old one and adds a new one
+// under the "# OVERRIDES" section; both lines carry marker comments so RemoveKeybinding
+// and RestoreKeybinding can undo the change. An earlier override is updated in place.
+// If no bind exists, it creates a new one running the binding's command, or the app's
+// package name. Exec binds get the app's Placement as a rule block, and the app's
+// own bind focuses an open window first for a SingleInstance app.
+// Both files are written atomically under the config lock; if omarchy.conf.yaml
+// can't be updated, bindings.conf is rolled back so the two never disagree.
+func AddKeybinding(app *config.Application, label, keybinding string) error {
+	return ReassignKeybinding(app, label, keybinding, nil)
+}
+
+// ReassignKeybinding is AddKeybinding that also takes the combination away from
+// conflicting binds (as returned by FindConflicts)
+// Conflicting binds in bindings.conf are commented out; binds defined in other
+// files are left in place.
+func ReassignKeybinding(app *config.Application, label, keybinding string, conflicts []Conflict) error {
+	if label == "" {
+		label = app.Name
+	}
+	command := app.PackageName
+	if i := app.FindKeybinding(label); i >= 0 && app.Keybindings[i].Command != "" {
+		command = app.Keybindings[i].Command
 	}
 
-	// Save config
-	// We need to use the writeConfig function from config package, but it's not exported
-	// So we'll need to implement it here or make it exported
-	// For now, let's use the same approach as writeConfig
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	hyprPath, lines, unlock, err := lockBindings()
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(cfg)
+	// Parse new keybinding (e.g. "SUPER SHIFT, A" or "Ctrl+Alt+T")
+	parsed, err := config.ParseKeybinding(keybinding)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
+	}
+	if !parsed.IsValid() {
+		return fmt.Errorf("keybinding is empty")
+	}
+	kb := parsed.Canonical()
+	newModifiers := kb.HyprMods()
+	newKey := kb.Key
+
+	hyprConfig := loadHyprConfig()
+
+	// Disable conflicting binds first, before line indexes shift
+	disableConflicts(lines, hyprPath, label, conflicts)
+
+	var bind *hyprconf.Bind
+	var unbinds []string
+	insertAt := -1
+	if blockStart, blockEnd, found := findOverrideBlock(lines, label); found {
+		// Our own earlier override: update it in place, keeping the unbind of
+		// the app's default bind
+		block := lines[blockStart:blockEnd]
+		bind = lastBindInBlock(block)
+		unbinds = originalUnbinds(block, bind)
+		if bind == nil {
+			bind = newExecBind("", "", label, command)
+			if external := findExternalBind(hyprConfig, hyprPath, label); external != nil {
+				bind = external
+			}
+		}
+		lines = removeLines(lines, blockStart, blockEnd)
+		insertAt = blockStart
+		logger.Log("AddKeybinding: Updating existing override at index %d", blockStart)
+	} else if originals := findOriginalBinds(hyprConfig, lines, hyprPath, app, label); len(originals) > 0 {
+		bind = originals[0]
+		for _, original := range originals {
+			// Comment out original line, marked so it can be restored
+			lines[original.Line-1] = disableLine(lines[original.Line-1], label)
+			logger.Log("AddKeybinding: Commented out original binding at line %d: %s", original.Line, original)
+		}
+	} else if external := findExternalBind(hyprConfig, hyprPath, label); external != nil {
+		// Binding from a file we must not edit (e.g. Omarchy's defaults): unbind it
+		logger.Log("AddKeybinding: Found existing binding at %s:%d, overriding with unbind", external.File, external.Line)
+		bind = external
+		warnIfParsedAfter(hyprConfig, external, hyprPath)
+		unbinds = append(unbinds, unbindLine(external))
+	} else {
+		// No existing binding - create new one
+		logger.Log("AddKeybinding: No existing binding found for '%s', creating new one", label)
+		bind = newExecBind("", "", label, command)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	// Keep the original flags, description and action; only the keys change, and
+	// exec binds pick up the app's placement and single-instance mode
+	bind.Mods = newModifiers
+	bind.Key = newKey
+	if bind.IsExec() {
+		if bind.Args, err = execArgs(app, label, bind.Args); err != nil {
+			return err
+		}
 	}
+	unbinds = append(unbinds, externalUnbinds(hyprConfig, hyprPath, conflicts)...)
 
-	return nil
+	override := append([]string{bindOverrideMarker(label)}, unbinds...)
+	override = append(override, bind.String(), bindOverrideEndMarker(label))
+	logger.Log("AddKeybinding: Created new bind line: %s", bind.String())
+
+	if insertAt >= 0 {
+		lines = insertLines(lines, insertAt, override...)
+	} else {
+		lines = insertOverride(lines, override...)
+	}
+
+	return writeBindingsAndConfig(hyprPath, lines, app.Name, appKeybinding(label, kb, bind))
 }
 
-// AddKeybinding adds or updates a keybinding in hyprland bindings.conf and omarchy.conf.yaml
-// If a binding already exists for the app, it comments out the old one and adds a new one.
-// If no binding exists, it creates a new one using the packageName as the command.
-func AddKeybinding(appName, packageName, keybinding string) error {
-	hyprPath, err := expandPath("~/.config/hypr/bindings.conf")
+// RemoveKeybinding removes one of app's keybindings from bindings.conf and omarchy.conf.yaml
+// The override is deleted, binds it took over from other apps are restored, and the
+// original bind stays disabled (unbound, if it lives outside bindings.conf).
+func RemoveKeybinding(app *config.Application, label string) error {
+	return resetKeybinding(app, label, false)
+}
+
+// RestoreKeybinding deletes the override of one of app's keybindings and un-comments
+// every line that was disabled for it, bringing back the original binding
+func RestoreKeybinding(app *config.Application, label string) error {
+	return resetKeybinding(app, label, true)
+}
+
+// resetKeybinding undoes the changes made for the keybinding labelled label, keeping its
+// original bind disabled unless restoreOriginal is set
+// Only lines carrying our markers are touched, never comments the user wrote.
+func resetKeybinding(app *config.Application, label string, restoreOriginal bool) error {
+	if label == "" {
+		label = app.Name
+	}
+
+	hyprPath, lines, unlock, err := lockBindings()
 	if err != nil {
-		return fmt.Errorf("failed to expand hypr config path: %w", err)
+		return err
+	}
+	defer unlock()
+
+	hyprConfig := loadHyprConfig()
+	changed := false
+
+	// Disable the app's binds that are still active first, while the parsed line
+	// numbers still match lines
+	if !restoreOriginal {
+		for _, original := range findOriginalBinds(hyprConfig, lines, hyprPath, app, label) {
+			lines[original.Line-1] = disableLine(lines[original.Line-1], label)
+			changed = true
+			logger.Log("resetKeybinding: Disabled bind at line %d", original.Line)
+		}
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(hyprPath); os.IsNotExist(err) {
-		return fmt.Errorf("bindings.conf not found at %s", hyprPath)
+	for {
+		start, end, found := findOverrideBlock(lines, label)
+		if !found {
+			break
+		}
+		lines = removeLines(lines, start, end)
+		changed = true
+		logger.Log("resetKeybinding: Removed override of '%s' at index %d", label, start)
 	}
 
-	// Read file
-	file, err := os.Open(hyprPath)
-	if err != nil {
-		return fmt.Errorf("failed to open bindings.conf: %w", err)
+	var keybinding config.Keybinding
+	var restored *hyprconf.Bind
+	for i, line := range lines {
+		owner, original, ok := parseDisabledLine(line)
+		if !ok || !strings.EqualFold(owner, label) {
+			continue
+		}
+		bind, err := hyprconf.ParseBindLine(original)
+		own := err == nil && isAppBind(hyprConfig, app, bind, label)
+		if own && !restoreOriginal {
+			continue
+		}
+
+		lines[i] = lineIndent(line) + original
+		changed = true
+		logger.Log("resetKeybinding: Restored line %d: %s", i+1, original)
+
+		if own {
+			// Variables (e.g. $mainMod) don't parse here; the loader fills those in on reload
+			if kb, err := config.ParseKeybinding(bind.Mods + ", " + bind.Key); err == nil {
+				keybinding = kb.Canonical()
+				restored = bind
+			}
+		}
 	}
-	defer file.Close()
 
-	// Read all lines
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	external := findExternalBind(hyprConfig, hyprPath, label)
+	if restoreOriginal && external != nil && !keybinding.IsValid() {
+		if kb, err := config.ParseKeybinding(external.Mods + ", " + external.Key); err == nil {
+			keybinding = kb.Canonical()
+			restored = external
+		}
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("failed to read bindings.conf: %w", err)
+
+	if !restoreOriginal && external != nil {
+		// The default bind can't be edited, keep it unbound
+		lines = insertOverride(lines, bindOverrideMarker(label), unbindLine(external), bindOverrideEndMarker(label))
+		changed = true
+		logger.Log("resetKeybinding: Unbound default bind at %s:%d", external.File, external.Line)
 	}
-	file.Close()
 
-	// Parse new keybinding (format: "MODIFIERS, KEY")
-	keybindingParts := strings.Split(keybinding, ",")
-	if len(keybindingParts) != 2 {
-		return fmt.Errorf("invalid keybinding format, expected 'MODIFIERS, KEY': %s", keybinding)
+	if !changed {
+		return fmt.Errorf("no keybinding for '%s' in bindings.conf", label)
 	}
-	newModifiers := strings.TrimSpace(keybindingParts[0])
-	newKey := strings.TrimSpace(keybindingParts[1])
 
-	// Find original bindd line (if exists)
-	originalLine, lineIndex, found := findOriginalBindLine(lines, appName)
+	return writeBindingsAndConfig(hyprPath, lines, app.Name, appKeybinding(label, keybinding, restored))
+}
 
-	var label, command string
-	if found {
-		logger.Log("AddKeybinding: Found existing binding at index %d: %s", lineIndex, originalLine)
+// KeybindingOverrides reports, for each of labels, whether bindings.conf has changes
+// made for the keybinding that RestoreKeybinding can undo. The file is read once.
+func KeybindingOverrides(labels []string) map[string]bool {
+	overrides := make(map[string]bool)
+	hyprPath, err := hyprconf.ExpandPath("~/.config/hypr/bindings.conf")
+	if err != nil {
+		return overrides
+	}
+	lines, err := readLines(hyprPath)
+	if err != nil {
+		return overrides
+	}
 
-		// Parse original line to extract label and command
-		_, _, label, command, err = parseBinddLine(originalLine)
-		if err != nil {
-			return fmt.Errorf("failed to parse original bindd line: %w", err)
+	for _, label := range labels {
+		overrides[label] = hasKeybindingOverride(lines, label)
+	}
+	return overrides
+}
+
+// hasKeybindingOverride reports whether lines hold an override block or disabled
+// binds for the keybinding labelled label
+func hasKeybindingOverride(lines []string, label string) bool {
+	if _, _, found := findOverrideBlock(lines, label); found {
+		return true
+	}
+	for _, line := range lines {
+		if owner, _, ok := parseDisabledLine(line); ok && strings.EqualFold(owner, label) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Comment out original line
-		lines[lineIndex] = "# " + lines[lineIndex]
-		logger.Log("AddKeybinding: Commented out original line")
-	} else {
-		// No existing binding - create new one
-		logger.Log("AddKeybinding: No existing binding found for '%s', creating new one", appName)
-		label = appName
-		command = packageName
+// lockBindings takes the config lock and reads bindings.conf
+// The caller must call unlock when done.
+func lockBindings() (hyprPath string, lines []string, unlock func(), err error) {
+	hyprPath, err = hyprconf.ExpandPath("~/.config/hypr/bindings.conf")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to expand hypr config path: %w", err)
 	}
 
-	// Check if "# OVERRIDES" section exists
-	hasOverrides := false
-	overridesIndex := -1
-	for i, line := range lines {
-		if strings.TrimSpace(line) == "# OVERRIDES" {
-			hasOverrides = true
-			overridesIndex = i
-			break
-		}
+	unlock, err = fsutil.Lock()
+	if err != nil {
+		return "", nil, nil, err
 	}
 
-	// Create new bindd line
-	newBinddLine := createBinddLine(newModifiers, newKey, label, command)
-	logger.Log("AddKeybinding: Created new bindd line: %s", newBinddLine)
+	// Check if file exists
+	if _, err := os.Stat(hyprPath); os.IsNotExist(err) {
+		unlock()
+		return "", nil, nil, fmt.Errorf("bindings.conf not found at %s", hyprPath)
+	}
 
-	// Add OVERRIDES section and new line
-	if !hasOverrides {
-		// Add at the end
-		lines = append(lines, "")
-		lines = append(lines, "# OVERRIDES")
-		lines = append(lines, newBinddLine)
-		logger.Log("AddKeybinding: Added OVERRIDES section and new bindd line at end of file")
-	} else {
-		// Insert after OVERRIDES marker
-		newLines := make([]string, 0, len(lines)+1)
-		newLines = append(newLines, lines[:overridesIndex+1]...)
-		newLines = append(newLines, newBinddLine)
-		newLines = append(newLines, lines[overridesIndex+1:]...)
-		lines = newLines
-		logger.Log("AddKeybinding: Added new bindd line after OVERRIDES marker")
+	// Read all lines
+	lines, err = readLines(hyprPath)
+	if err != nil {
+		unlock()
+		return "", nil, nil, fmt.Errorf("failed to read bindings.conf: %w", err)
 	}
+	return hyprPath, lines, unlock, nil
+}
 
-	// Write file back
-	if err := os.WriteFile(hyprPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+// writeBindingsAndConfig writes bindings.conf and records the app's keybinding in
+// omarchy.conf.yaml; if the config can't be updated, bindings.conf is rolled back
+// so the two never disagree
+func writeBindingsAndConfig(hyprPath string, lines []string, appName string, binding config.AppKeybinding) error {
+	tx := fsutil.NewTransaction()
+	if err := tx.WriteFile(hyprPath, joinLines(lines), 0644); err != nil {
 		return fmt.Errorf("failed to write bindings.conf: %w", err)
 	}
 
-	logger.Log("AddKeybinding: Updated bindings.conf successfully")
+	logger.Log("writeBindingsAndConfig: Updated bindings.conf successfully")
 
-	// Update omarchy.conf.yaml
-	if err := updateOmarchyConfig(appName, keybinding); err != nil {
-		logger.Log("AddKeybinding: Warning - failed to update omarchy.conf.yaml: %v", err)
-		// Don't fail the whole operation if config update fails
+	// Update omarchy.conf.yaml, restoring bindings.conf if that fails
+	if err := updateOmarchyConfig(appName, binding); err != nil {
+		logger.Log("writeBindingsAndConfig: Failed to update omarchy.conf.yaml, rolling back bindings.conf: %v", err)
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logger.Log("writeBindingsAndConfig: Rollback failed: %v", rbErr)
+			return fmt.Errorf("failed to update omarchy.conf.yaml (%v) and to restore bindings.conf: %w", err, rbErr)
+		}
+		return fmt.Errorf("failed to update omarchy.conf.yaml: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,67 @@
+package hypr
+
+import (
+	"omarchy-tui/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeJQ puts an executable jq on a PATH of its own, or leaves PATH empty
+func fakeJQ(t *testing.T, installed bool) {
+	t.Helper()
+	dir := t.TempDir()
+	if installed {
+		if err := os.WriteFile(filepath.Join(dir, "jq"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestExecArgs(t *testing.T) {
+	fakeJQ(t, true)
+	app := &config.Application{Name: "Firefox", PackageName: "firefox", SingleInstance: true}
+
+	for _, label := range []string{"Firefox", "firefox", "FIREFOX"} {
+		args, err := execArgs(app, label, "firefox")
+		if err != nil {
+			t.Fatalf("execArgs(%q): %v", label, err)
+		}
+		if launch, ok := config.SplitFocusOrLaunch(args); !ok || launch != "firefox" {
+			t.Errorf("execArgs(%q) = %q, want a focus-or-launch snippet", label, args)
+		}
+	}
+
+	// Other bindings of the app launch it as they are
+	if args, err := execArgs(app, "Private window", "firefox --private-window"); err != nil || args != "firefox --private-window" {
+		t.Errorf("execArgs for another label = %q, %v", args, err)
+	}
+
+	// Rewriting an existing snippet doesn't nest it
+	args, _ := execArgs(app, "Firefox", "firefox")
+	again, err := execArgs(app, "Firefox", args)
+	if err != nil || again != args {
+		t.Errorf("execArgs on its own output = %q, %v, want %q", again, err, args)
+	}
+
+	app.Placement = config.Placement{Workspace: "2", Silent: true}
+	if args, _ := execArgs(app, "Firefox", "firefox"); !strings.HasPrefix(args, "[workspace 2 silent] window=$(") {
+		t.Errorf("execArgs with placement = %q", args)
+	}
+}
+
+func TestExecArgsWithoutJQ(t *testing.T) {
+	fakeJQ(t, false)
+	app := &config.Application{Name: "Firefox", PackageName: "firefox", SingleInstance: true}
+
+	if _, err := execArgs(app, "firefox", "firefox"); err == nil || !strings.Contains(err.Error(), "jq") {
+		t.Errorf("execArgs error = %v, want jq reported missing", err)
+	}
+	// Binds that don't focus an open window don't need it
+	app.SingleInstance = false
+	if args, err := execArgs(app, "Firefox", "firefox"); err != nil || args != "firefox" {
+		t.Errorf("execArgs = %q, %v", args, err)
+	}
+}
@@ -0,0 +1,76 @@
+package hypr
+
+import (
+	"encoding/json"
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/logger"
+	"strings"
+)
+
+// Client is a window as reported by Hyprland's j/clients request
+type Client struct {
+	Address        string `json:"address"`
+	Class          string `json:"class"`
+	Title          string `json:"title"`
+	InitialClass   string `json:"initialClass"`
+	InitialTitle   string `json:"initialTitle"`
+	PID            int    `json:"pid"`
+	FocusHistoryID int    `json:"focusHistoryID"` // 0 is the focused window, higher was focused longer ago
+	Workspace      struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"workspace"`
+}
+
+// Clients returns the windows currently open in Hyprland
+func (c *IPC) Clients() ([]Client, error) {
+	reply, err := c.Request("j/clients")
+	if err != nil {
+		return nil, err
+	}
+	var clients []Client
+	if err := json.Unmarshal(reply, &clients); err != nil {
+		return nil, fmt.Errorf("failed to parse Hyprland clients: %w", err)
+	}
+	return clients, nil
+}
+
+// FocusWindow focuses the window with the given address, switching workspace if needed
+func (c *IPC) FocusWindow(address string) error {
+	return c.Dispatch("focuswindow", "address:"+address)
+}
+
+// FindWindow returns the most recently focused client whose class or initial class is
+// class (ignoring case), or whose initial title is title; nil if there is none
+// Empty class or title never match. config.FocusOrLaunchCommand selects the same way.
+func FindWindow(clients []Client, class, title string) *Client {
+	var found *Client
+	for i := range clients {
+		client := &clients[i]
+		matches := class != "" && (strings.EqualFold(client.Class, class) || strings.EqualFold(client.InitialClass, class))
+		matches = matches || (title != "" && client.InitialTitle == title)
+		if matches && (found == nil || client.FocusHistoryID < found.FocusHistoryID) {
+			found = client
+		}
+	}
+	return found
+}
+
+// FocusApp focuses an open window of a single-instance app
+// It reports false when the app has no window, and it should be launched instead.
+func (c *IPC) FocusApp(app *config.Application) (bool, error) {
+	clients, err := c.Clients()
+	if err != nil {
+		return false, err
+	}
+	window := FindWindow(clients, app.WindowClass(), app.WindowTitle)
+	if window == nil {
+		return false, nil
+	}
+	if err := c.FocusWindow(window.Address); err != nil {
+		return false, err
+	}
+	logger.Log("FocusApp: Focused %s window %s (%s) on workspace %s", app.Name, window.Address, window.Title, window.Workspace.Name)
+	return true, nil
+}
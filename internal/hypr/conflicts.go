@@ -0,0 +1,75 @@
+package hypr
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hyprconf"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Conflict is an existing bind that uses the same key combination
+type Conflict struct {
+	Bind *hyprconf.Bind
+}
+
+// Action describes the bound action, including its description if it has one
+func (c Conflict) Action() string {
+	if c.Bind.HasDescription() && c.Bind.Description != "" {
+		return fmt.Sprintf("%s (%s)", c.Bind.Description, c.Bind.Action())
+	}
+	return c.Bind.Action()
+}
+
+// Location returns "file:line" with the home directory shortened to ~
+func (c Conflict) Location() string {
+	file := c.Bind.File
+	if usr, err := user.Current(); err == nil && strings.HasPrefix(file, usr.HomeDir+"/") {
+		file = "~" + file[len(usr.HomeDir):]
+	}
+	return fmt.Sprintf("%s:%d", file, c.Bind.Line)
+}
+
+// FindConflicts returns the binds in the Hyprland config that already use keybinding
+// Every bind flavor and dispatcher is checked, not just exec binds. Binds inside a
+// submap, binds removed by a later unbind, and the bind being edited (app's keybinding
+// labelled label, "" for its main binding) are ignored. app's other binds do conflict.
+func FindConflicts(keybinding config.Keybinding, app *config.Application, label string) ([]Conflict, error) {
+	if !keybinding.IsValid() {
+		return nil, nil
+	}
+
+	hyprConfig, err := config.LoadHyprConfig()
+	if err != nil {
+		return nil, err
+	}
+	if hyprConfig == nil {
+		return nil, nil
+	}
+
+	var conflicts []Conflict
+	for _, bind := range hyprConfig.ActiveBinds() {
+		if bind.Combo() != keybinding.Combo() || isEditedBind(bind, app, label) {
+			continue
+		}
+		conflicts = append(conflicts, Conflict{Bind: bind})
+	}
+	return conflicts, nil
+}
+
+// isEditedBind reports whether bind is app's keybinding labelled label
+func isEditedBind(bind *hyprconf.Bind, app *config.Application, label string) bool {
+	return app != nil && app.MatchesBind(bind, label)
+}
+
+// isSameFile reports whether two paths refer to the same file
+func isSameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,42 @@
+package hypr
+
+import (
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hyprconf"
+	"testing"
+)
+
+func TestIsEditedBind(t *testing.T) {
+	app := &config.Application{Name: "Firefox", PackageName: "firefox"}
+	tests := []struct {
+		line  string
+		label string
+		want  bool
+	}{
+		{"bind = SUPER, B, exec, firefox", "", true},
+		{"bind = SUPER, B, exec, uwsm app -- firefox", "", true},
+		{"bind = SUPER, B, exec, uwsm app -- firefox --private-window", "Firefox", true},
+		{"bindd = SUPER, B, Firefox, exec, uwsm app -- firefox", "", true},
+		{"bindd = SUPER SHIFT, B, Private, exec, firefox --private-window", "Private", true},
+		// Another keybinding of the same app is a conflict
+		{"bindd = SUPER SHIFT, B, Private, exec, firefox --private-window", "", false},
+		{"bindd = SUPER, B, Chromium, exec, chromium", "", false},
+		{"bind = SUPER, B, exec, chromium", "", false},
+		{"bind = SUPER, B, killactive,", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			bind, err := hyprconf.ParseBindLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseBindLine: %v", err)
+			}
+			if got := isEditedBind(bind, app, tt.label); got != tt.want {
+				t.Errorf("isEditedBind(%q) = %v, want %v", tt.label, got, tt.want)
+			}
+		})
+	}
+	bind, _ := hyprconf.ParseBindLine("bind = SUPER, B, exec, firefox")
+	if isEditedBind(bind, nil, "") {
+		t.Error("isEditedBind without an app = true, want false")
+	}
+}
@@ -0,0 +1,248 @@
+package hypr
+
+import (
+	"encoding/json"
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hyprconf"
+	"sort"
+	"strings"
+)
+
+// LiveBind is a bind as reported by Hyprland's j/binds request
+type LiveBind struct {
+	Locked         bool   `json:"locked"`
+	Mouse          bool   `json:"mouse"`
+	Release        bool   `json:"release"`
+	Repeat         bool   `json:"repeat"`
+	NonConsuming   bool   `json:"non_consuming"`
+	HasDescription bool   `json:"has_description"`
+	Modmask        uint32 `json:"modmask"`
+	Submap         string `json:"submap"`
+	Key            string `json:"key"`
+	Keycode        int    `json:"keycode"`
+	Description    string `json:"description"`
+	Dispatcher     string `json:"dispatcher"`
+	Arg            string `json:"arg"`
+}
+
+// modmaskNames are Hyprland's modifier bits, in the order they are rendered
+var modmaskNames = []struct {
+	bit  uint32
+	name string
+}{
+	{64, "SUPER"},
+	{4, "CTRL"},
+	{8, "ALT"},
+	{1, "SHIFT"},
+	{2, "CAPS"},
+	{16, "MOD2"},
+	{32, "MOD3"},
+	{128, "MOD5"},
+}
+
+// Mods returns the modifier names of the bind, e.g. "SUPER SHIFT"
+func (b LiveBind) Mods() string {
+	var mods []string
+	for _, mod := range modmaskNames {
+		if b.Modmask&mod.bit != 0 {
+			mods = append(mods, mod.name)
+		}
+	}
+	return strings.Join(mods, " ")
+}
+
+// KeyName returns the bound key, "code:N" for binds made by keycode
+func (b LiveBind) KeyName() string {
+	if b.Key == "" && b.Keycode != 0 {
+		return fmt.Sprintf("code:%d", b.Keycode)
+	}
+	return b.Key
+}
+
+// Combo returns the normalized key combination of the bind (see hyprconf.Combo)
+func (b LiveBind) Combo() string {
+	return hyprconf.Combo(b.Mods(), b.KeyName())
+}
+
+// Action returns the dispatcher and arguments of the bind as a config file writes them
+// Hyprland reports mouse binds (bindm) with the "mouse" dispatcher and the action,
+// e.g. movewindow, as its argument; bindm lines name the action as the dispatcher.
+func (b LiveBind) Action() (dispatcher, arg string) {
+	if b.Mouse && strings.EqualFold(b.Dispatcher, "mouse") {
+		return strings.TrimSpace(b.Arg), ""
+	}
+	return b.Dispatcher, b.Arg
+}
+
+// Binds returns the binds currently active in Hyprland
+func (c *IPC) Binds() ([]LiveBind, error) {
+	reply, err := c.Request("j/binds")
+	if err != nil {
+		return nil, err
+	}
+	var binds []LiveBind
+	if err := json.Unmarshal(reply, &binds); err != nil {
+		return nil, fmt.Errorf("failed to parse Hyprland binds: %w", err)
+	}
+	return binds, nil
+}
+
+// DriftKind classifies a difference between the live binds and the config files
+type DriftKind int
+
+const (
+	DriftNotInFiles        DriftKind = iota // active in Hyprland, in no parsed file
+	DriftNotLoaded                          // in the files, not active in Hyprland
+	DriftDispatcherChanged                  // same keys, different dispatcher or arguments
+)
+
+// String describes the kind of drift
+func (k DriftKind) String() string {
+	switch k {
+	case DriftNotInFiles:
+		return "Active but not in any file"
+	case DriftNotLoaded:
+		return "In a file but not loaded"
+	case DriftDispatcherChanged:
+		return "Dispatcher differs"
+	}
+	return "Unknown"
+}
+
+// Drift is one bind where Hyprland and the config files disagree
+// Live is nil for DriftNotLoaded, File is nil for DriftNotInFiles.
+type Drift struct {
+	Kind DriftKind
+	Live *LiveBind
+	File *hyprconf.Bind
+}
+
+// Keys returns the key combination the drift is about, e.g. "SUPER SHIFT, B"
+func (d Drift) Keys() string {
+	var mods, key string
+	if d.File != nil {
+		mods, key = d.File.Mods, d.File.Key
+	} else {
+		mods, key = d.Live.Mods(), d.Live.KeyName()
+	}
+	if kb, err := config.ParseKeybinding(mods + ", " + key); err == nil && kb.IsValid() {
+		return kb.Canonical().String()
+	}
+	return strings.TrimSpace(mods + ", " + key)
+}
+
+// LiveAction describes what the key does in Hyprland right now
+func (d Drift) LiveAction() string {
+	if d.Live == nil {
+		return ""
+	}
+	dispatcher, arg := d.Live.Action()
+	return strings.TrimSpace(dispatcher + " " + arg)
+}
+
+// FileAction describes what the config files say the key does, with its location
+func (d Drift) FileAction() string {
+	if d.File == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", d.File.Action(), Conflict{Bind: d.File}.Location())
+}
+
+// FindDrift compares the binds active in the running Hyprland with the parsed config
+// Returns ErrNotRunning when Hyprland can't be reached.
+func FindDrift() ([]Drift, error) {
+	ipc, err := NewIPC()
+	if err != nil {
+		return nil, err
+	}
+	live, err := ipc.Binds()
+	if err != nil {
+		return nil, err
+	}
+
+	hyprConfig, err := config.LoadHyprConfig()
+	if err != nil {
+		return nil, err
+	}
+	var parsed []*hyprconf.Bind
+	if hyprConfig != nil {
+		parsed = hyprConfig.ActiveBinds()
+	}
+	return compareBinds(live, parsed), nil
+}
+
+// compareBinds pairs live binds with parsed binds by key combination
+// Binds with the same combination and action are in sync; leftovers on both sides with
+// the same combination differ in dispatcher, the rest exist on one side only.
+// Submap binds are skipped, as ActiveBinds leaves them out.
+func compareBinds(live []LiveBind, parsed []*hyprconf.Bind) []Drift {
+	liveByCombo := make(map[string][]*LiveBind)
+	var combos []string
+	for i := range live {
+		if live[i].Submap != "" {
+			continue
+		}
+		combo := live[i].Combo()
+		if _, ok := liveByCombo[combo]; !ok {
+			combos = append(combos, combo)
+		}
+		liveByCombo[combo] = append(liveByCombo[combo], &live[i])
+	}
+
+	fileByCombo := make(map[string][]*hyprconf.Bind)
+	for _, bind := range parsed {
+		combo := bind.Combo()
+		if _, ok := liveByCombo[combo]; !ok {
+			if _, ok := fileByCombo[combo]; !ok {
+				combos = append(combos, combo)
+			}
+		}
+		fileByCombo[combo] = append(fileByCombo[combo], bind)
+	}
+	sort.Strings(combos)
+
+	var drifts []Drift
+	for _, combo := range combos {
+		liveBinds, fileBinds := unmatchedBinds(liveByCombo[combo], fileByCombo[combo])
+		for len(liveBinds) > 0 && len(fileBinds) > 0 {
+			drifts = append(drifts, Drift{Kind: DriftDispatcherChanged, Live: liveBinds[0], File: fileBinds[0]})
+			liveBinds, fileBinds = liveBinds[1:], fileBinds[1:]
+		}
+		for _, bind := range liveBinds {
+			drifts = append(drifts, Drift{Kind: DriftNotInFiles, Live: bind})
+		}
+		for _, bind := range fileBinds {
+			drifts = append(drifts, Drift{Kind: DriftNotLoaded, File: bind})
+		}
+	}
+	return drifts
+}
+
+// unmatchedBinds drops the live and parsed binds that run the same action
+func unmatchedBinds(live []*LiveBind, parsed []*hyprconf.Bind) ([]*LiveBind, []*hyprconf.Bind) {
+	var leftLive []*LiveBind
+	remaining := append([]*hyprconf.Bind(nil), parsed...)
+	for _, liveBind := range live {
+		matched := false
+		for i, bind := range remaining {
+			if sameAction(liveBind, bind) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			leftLive = append(leftLive, liveBind)
+		}
+	}
+	return leftLive, remaining
+}
+
+// sameAction reports whether a live bind and a parsed bind run the same dispatcher
+// with the same arguments (whitespace-insensitive)
+func sameAction(live *LiveBind, bind *hyprconf.Bind) bool {
+	dispatcher, arg := live.Action()
+	return strings.EqualFold(dispatcher, bind.Dispatcher) &&
+		strings.Join(strings.Fields(arg), " ") == strings.Join(strings.Fields(bind.Args), " ")
+}
@@ -0,0 +1,115 @@
+package hypr
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"reflect"
+	"testing"
+)
+
+// parseBinds parses bind lines or fails the test
+func parseBinds(t *testing.T, lines ...string) []*hyprconf.Bind {
+	t.Helper()
+	var binds []*hyprconf.Bind
+	for _, line := range lines {
+		bind, err := hyprconf.ParseBindLine(line)
+		if err != nil {
+			t.Fatalf("ParseBindLine(%q): %v", line, err)
+		}
+		binds = append(binds, bind)
+	}
+	return binds
+}
+
+func TestSameAction(t *testing.T) {
+	tests := []struct {
+		name string
+		live LiveBind
+		line string
+		want bool
+	}{
+		{"same exec", LiveBind{Dispatcher: "exec", Arg: "uwsm app -- firefox"}, "bind = SUPER, B, exec, uwsm app -- firefox", true},
+		{"whitespace differs", LiveBind{Dispatcher: "exec", Arg: "uwsm  app --  firefox"}, "bind = SUPER, B, exec, uwsm app -- firefox", true},
+		{"dispatcher case", LiveBind{Dispatcher: "killactive"}, "bind = SUPER, W, KillActive,", true},
+		{"other arguments", LiveBind{Dispatcher: "exec", Arg: "chromium"}, "bind = SUPER, B, exec, firefox", false},
+		{"other dispatcher", LiveBind{Dispatcher: "togglefloating"}, "bind = SUPER, V, pseudo,", false},
+		{"mouse bind", LiveBind{Mouse: true, Dispatcher: "mouse", Arg: "movewindow"}, "bindm = SUPER, mouse:272, movewindow", true},
+		{"mouse bind, other action", LiveBind{Mouse: true, Dispatcher: "mouse", Arg: "resizewindow"}, "bindm = SUPER, mouse:272, movewindow", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bind := parseBinds(t, tt.line)[0]
+			if got := sameAction(&tt.live, bind); got != tt.want {
+				t.Errorf("sameAction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmatchedBinds(t *testing.T) {
+	live := []*LiveBind{
+		{Dispatcher: "exec", Arg: "firefox"},
+		{Dispatcher: "exec", Arg: "chromium"},
+		{Mouse: true, Dispatcher: "mouse", Arg: "movewindow"},
+	}
+	parsed := parseBinds(t,
+		"bindm = SUPER, mouse:272, movewindow",
+		"bind = SUPER, B, exec, firefox",
+		"bind = SUPER, B, exec, firefox",
+	)
+
+	leftLive, leftParsed := unmatchedBinds(live, parsed)
+	if len(leftLive) != 1 || leftLive[0] != live[1] {
+		t.Errorf("unmatched live binds = %v, want only chromium", leftLive)
+	}
+	// Each live bind matches one parsed bind, so the duplicate is left over
+	if len(leftParsed) != 1 || leftParsed[0] != parsed[2] {
+		t.Errorf("unmatched parsed binds = %v, want the second firefox bind", leftParsed)
+	}
+	if len(parsed) != 3 {
+		t.Errorf("unmatchedBinds changed its input: %v", parsed)
+	}
+}
+
+func TestCompareBinds(t *testing.T) {
+	const super, shift = 64, 1
+	live := []LiveBind{
+		{Modmask: super, Key: "B", Dispatcher: "exec", Arg: "firefox"},
+		{Modmask: super, Key: "mouse:272", Mouse: true, Dispatcher: "mouse", Arg: "movewindow"},
+		{Modmask: super, Key: "mouse:273", Mouse: true, Dispatcher: "mouse", Arg: "resizewindow"},
+		{Modmask: super | shift, Key: "N", Dispatcher: "exec", Arg: "obsidian"},
+		{Modmask: super, Key: "T", Dispatcher: "exec", Arg: "alacritty"},
+		{Modmask: super, Key: "X", Dispatcher: "exec", Arg: "reboot", Submap: "power"},
+	}
+	parsed := parseBinds(t,
+		"bind = SUPER, B, exec, firefox",
+		"bindm = SUPER, mouse:272, movewindow",
+		"bindm = SUPER, mouse:273, movewindow",
+		"bind = SUPER, M, exec, spotify",
+		"bind = SUPER, T, exec, foot",
+	)
+
+	type drift struct {
+		kind DriftKind
+		keys string
+		live string
+		file string
+	}
+	var got []drift
+	for _, d := range compareBinds(live, parsed) {
+		file := ""
+		if d.File != nil {
+			file = d.File.Action()
+		}
+		got = append(got, drift{d.Kind, d.Keys(), d.LiveAction(), file})
+	}
+	// Sorted by normalized combination; the in-sync binds and the submap bind are left out
+	want := []drift{
+		{DriftNotInFiles, "SUPER SHIFT, N", "exec obsidian", ""},
+		{DriftNotLoaded, "SUPER, M", "", "exec spotify"},
+		{DriftDispatcherChanged, "SUPER, mouse:273", "resizewindow", "movewindow"},
+		{DriftDispatcherChanged, "SUPER, T", "exec alacritty", "exec foot"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compareBinds:\n%+v\nwant:\n%+v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package hypr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"omarchy-tui/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotRunning is returned when no Hyprland instance can be reached
+var ErrNotRunning = errors.New("Hyprland is not running")
+
+// ipcTimeout bounds a whole request, so a hung compositor never freezes the TUI
+const ipcTimeout = 2 * time.Second
+
+// IPC is a client for Hyprland's request socket (.socket.sock)
+// Each request opens a new connection: Hyprland answers and closes it.
+type IPC struct {
+	SocketPath string
+	Timeout    time.Duration
+}
+
+// NewIPC returns a client for the running Hyprland instance
+// The socket lives in $XDG_RUNTIME_DIR/hypr/$HYPRLAND_INSTANCE_SIGNATURE, or in /tmp/hypr
+// for Hyprland versions before 0.40.
+func NewIPC() (*IPC, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return nil, ErrNotRunning
+	}
+
+	var candidates []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "hypr", signature, ".socket.sock"))
+	}
+	candidates = append(candidates, filepath.Join("/tmp/hypr", signature, ".socket.sock"))
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return &IPC{SocketPath: path, Timeout: ipcTimeout}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no socket for instance %s", ErrNotRunning, signature)
+}
+
+// Request sends a raw command (e.g. "reload" or "j/clients") and returns the reply
+func (c *IPC) Request(command string) ([]byte, error) {
+	conn, err := net.DialTimeout("unix", c.SocketPath, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Hyprland: %w", err)
+	}
+	defer conn.Close()
+
+	if c.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+			return nil, fmt.Errorf("failed to set Hyprland socket deadline: %w", err)
+		}
+	}
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return nil, fmt.Errorf("failed to send %q to Hyprland: %w", command, err)
+	}
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Hyprland reply to %q: %w", command, err)
+	}
+	return reply, nil
+}
+
+// Reload makes Hyprland re-read its config files
+func (c *IPC) Reload() error {
+	reply, err := c.Request("reload")
+	if err != nil {
+		return err
+	}
+	if text := strings.TrimSpace(string(reply)); text != "ok" {
+		return fmt.Errorf("Hyprland refused to reload: %s", text)
+	}
+	return nil
+}
+
+// Dispatch runs a dispatcher, e.g. Dispatch("exec", "[workspace 2 silent] firefox")
+func (c *IPC) Dispatch(dispatcher, args string) error {
+	reply, err := c.Request(strings.TrimSpace("dispatch " + dispatcher + " " + args))
+	if err != nil {
+		return err
+	}
+	if text := strings.TrimSpace(string(reply)); text != "ok" {
+		return fmt.Errorf("Hyprland refused to %s: %s", dispatcher, text)
+	}
+	return nil
+}
+
+// ConfigErrors returns the errors Hyprland found in its config on the last (re)load
+func (c *IPC) ConfigErrors() ([]string, error) {
+	reply, err := c.Request("j/configerrors")
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigErrors(reply)
+}
+
+// parseConfigErrors decodes a j/configerrors reply
+// Hyprland answers [""] when there are no errors, so empty entries are dropped.
+func parseConfigErrors(reply []byte) ([]string, error) {
+	var raw []string
+	if err := json.Unmarshal(reply, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Hyprland config errors: %w", err)
+	}
+
+	var errs []string
+	for _, entry := range raw {
+		for _, line := range strings.Split(entry, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				errs = append(errs, line)
+			}
+		}
+	}
+	return errs, nil
+}
+
+// ReloadConfig reloads the running Hyprland and returns the config errors it reports
+// Returns ErrNotRunning when Hyprland can't be reached (e.g. the TUI runs over SSH).
+func ReloadConfig() ([]string, error) {
+	ipc, err := NewIPC()
+	if err != nil {
+		return nil, err
+	}
+	if err := ipc.Reload(); err != nil {
+		return nil, err
+	}
+	errs, err := ipc.ConfigErrors()
+	if err != nil {
+		return nil, err
+	}
+	logger.Log("ReloadConfig: Hyprland reloaded with %d config errors", len(errs))
+	return errs, nil
+}
@@ -0,0 +1,244 @@
+package hypr
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHyprland answers requests on a Hyprland-style socket with canned replies
+// and records the requests it got
+type fakeHyprland struct {
+	mu       sync.Mutex
+	requests []string
+	done     chan struct{} // closed when the test ends
+}
+
+// startFakeHyprland listens on dir/.socket.sock; unknown requests get "unknown request"
+// and a reply of "" means the connection is left hanging
+func startFakeHyprland(t *testing.T, dir string, replies map[string]string) (*fakeHyprland, string) {
+	t.Helper()
+	path := filepath.Join(dir, ".socket.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	fake := &fakeHyprland{done: make(chan struct{})}
+	t.Cleanup(func() {
+		listener.Close()
+		close(fake.done)
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fake.serve(conn, replies)
+		}
+	}()
+	return fake, path
+}
+
+// serve reads one request and writes its reply, like Hyprland does
+func (f *fakeHyprland) serve(conn net.Conn, replies map[string]string) {
+	buf := make([]byte, 8192)
+	n, err := conn.Read(buf)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	request := string(buf[:n])
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+
+	reply, ok := replies[request]
+	if !ok {
+		reply = "unknown request"
+	}
+	if reply == "" {
+		// Hang until the test ends
+		<-f.done
+		conn.Close()
+		return
+	}
+	conn.Write([]byte(reply))
+	conn.Close()
+}
+
+// received returns the requests seen so far
+func (f *fakeHyprland) received() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func TestIPCRequest(t *testing.T) {
+	fake, path := startFakeHyprland(t, t.TempDir(), map[string]string{
+		"j/clients": `[{"class":"firefox"}]`,
+		"reload":    "ok",
+		"dispatch exec [workspace 2 silent] foot": "ok",
+		"dispatch focuswindow nothing":            "No such window found",
+	})
+	ipc := &IPC{SocketPath: path, Timeout: time.Second}
+
+	reply, err := ipc.Request("j/clients")
+	if err != nil || string(reply) != `[{"class":"firefox"}]` {
+		t.Errorf("Request = %q, %v", reply, err)
+	}
+	if err := ipc.Reload(); err != nil {
+		t.Errorf("Reload: %v", err)
+	}
+	if err := ipc.Dispatch("exec", "[workspace 2 silent] foot"); err != nil {
+		t.Errorf("Dispatch: %v", err)
+	}
+	if err := ipc.Dispatch("focuswindow", "nothing"); err == nil || !strings.Contains(err.Error(), "No such window found") {
+		t.Errorf("refused Dispatch error = %v, want Hyprland's reply", err)
+	}
+
+	want := []string{"j/clients", "reload", "dispatch exec [workspace 2 silent] foot", "dispatch focuswindow nothing"}
+	if got := fake.received(); !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+}
+
+func TestIPCReloadRefused(t *testing.T) {
+	_, path := startFakeHyprland(t, t.TempDir(), map[string]string{"reload": "config is locked"})
+	ipc := &IPC{SocketPath: path, Timeout: time.Second}
+	if err := ipc.Reload(); err == nil || !strings.Contains(err.Error(), "config is locked") {
+		t.Errorf("Reload error = %v, want Hyprland's reply", err)
+	}
+}
+
+func TestIPCRequestTimeout(t *testing.T) {
+	_, path := startFakeHyprland(t, t.TempDir(), map[string]string{"j/clients": ""})
+	ipc := &IPC{SocketPath: path, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	if _, err := ipc.Request("j/clients"); err == nil {
+		t.Error("Request to a hung Hyprland succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Request took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestIPCRequestNoListener(t *testing.T) {
+	ipc := &IPC{SocketPath: filepath.Join(t.TempDir(), ".socket.sock"), Timeout: time.Second}
+	if _, err := ipc.Request("reload"); err == nil || !strings.Contains(err.Error(), "failed to connect") {
+		t.Errorf("Request error = %v, want a connection failure", err)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    []string
+		wantErr bool
+	}{
+		{"no errors", `[""]`, nil, false},
+		{"empty list", `[]`, nil, false},
+		{"one error", `["Config error in file bindings.conf at line 3: Invalid dispatcher"]`, []string{"Config error in file bindings.conf at line 3: Invalid dispatcher"}, false},
+		{
+			"several lines in one entry",
+			`["line 3: bad bind\nline 7: unknown keyword\n", "  line 9: missing value  "]`,
+			[]string{"line 3: bad bind", "line 7: unknown keyword", "line 9: missing value"},
+			false,
+		},
+		{"not json", "unknown request", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfigErrors([]byte(tt.reply))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfigErrors error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseConfigErrors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPC(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	if _, err := NewIPC(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("NewIPC without a signature = %v, want ErrNotRunning", err)
+	}
+
+	signature := "test_" + filepath.Base(runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", signature)
+	if _, err := NewIPC(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("NewIPC without a socket = %v, want ErrNotRunning", err)
+	}
+
+	// Hyprland before 0.40 kept its sockets in /tmp/hypr
+	legacyDir := filepath.Join("/tmp/hypr", signature)
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Skipf("can't create %s: %v", legacyDir, err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(legacyDir)
+		os.Remove("/tmp/hypr") // only if nothing else uses it
+	})
+	_, legacyPath := startFakeHyprland(t, legacyDir, nil)
+	if ipc, err := NewIPC(); err != nil || ipc.SocketPath != legacyPath {
+		t.Errorf("NewIPC = %+v, %v, want the /tmp/hypr socket", ipc, err)
+	}
+
+	// The socket under $XDG_RUNTIME_DIR wins
+	dir := filepath.Join(runtimeDir, "hypr", signature)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, path := startFakeHyprland(t, dir, nil)
+	ipc, err := NewIPC()
+	if err != nil || ipc.SocketPath != path {
+		t.Errorf("NewIPC = %+v, %v, want %s", ipc, err, path)
+	}
+	if ipc != nil && ipc.Timeout != ipcTimeout {
+		t.Errorf("Timeout = %v, want %v", ipc.Timeout, ipcTimeout)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "test_reload")
+
+	// No socket, e.g. the TUI runs over SSH: callers skip the reload
+	if _, err := ReloadConfig(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("ReloadConfig without Hyprland = %v, want ErrNotRunning", err)
+	}
+
+	dir := filepath.Join(runtimeDir, "hypr", "test_reload")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake, _ := startFakeHyprland(t, dir, map[string]string{
+		"reload":         "ok",
+		"j/configerrors": `["bindings.conf:12: Invalid dispatcher"]`,
+	})
+	errs, err := ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if want := []string{"bindings.conf:12: Invalid dispatcher"}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("config errors = %q, want %q", errs, want)
+	}
+	if got, want := fake.received(), []string{"reload", "j/configerrors"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package hypr
+
+import (
+	"omarchy-tui/internal/hyprconf"
+	"strings"
+)
+
+// Lines written by omarchy-tui carry marker comments so they can be told apart from
+// the user's own comments and undone later:
+//
+//	# OVERRIDES: bind Firefox                               <- our override of Firefox's bind
+//	bindd = SUPER, B, Firefox, exec, firefox
+//	# END OVERRIDES: bind Firefox
+//	# OMARCHY-TUI DISABLED [Firefox]: bindd = SUPER SHIFT, F, Firefox, exec, firefox
+
+// overridesSection is the marker the override binds are inserted under
+const overridesSection = "# OVERRIDES"
+
+// overrideEndPrefix starts the comment closing a keybinding's override block
+const overrideEndPrefix = "# END OVERRIDES: bind "
+
+// disabledBindPrefix starts a bind line disabled on behalf of a keybinding
+const disabledBindPrefix = "# OMARCHY-TUI DISABLED ["
+
+// bindOverrideMarker returns the comment placed above a keybinding's override bind
+func bindOverrideMarker(label string) string {
+	return overridesSection + ": bind " + label
+}
+
+// bindOverrideEndMarker returns the comment closing a keybinding's override block
+func bindOverrideEndMarker(label string) string {
+	return overrideEndPrefix + label
+}
+
+// disableLine comments out line, recording the keybinding it was disabled for
+// Indentation is kept in front of the marker.
+func disableLine(line, label string) string {
+	return lineIndent(line) + disabledBindPrefix + label + "]: " + strings.TrimSpace(line)
+}
+
+// lineIndent returns the leading whitespace of line
+func lineIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// parseDisabledLine returns the keybinding label and original text of a line disabled by disableLine
+func parseDisabledLine(line string) (label, original string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, disabledBindPrefix) {
+		return "", "", false
+	}
+	return strings.Cut(trimmed[len(disabledBindPrefix):], "]: ")
+}
+
+// isOverrideMarker reports whether line is the override marker of the keybinding labelled label
+func isOverrideMarker(line, label string) bool {
+	trimmed := strings.TrimSpace(line)
+	prefix := bindOverrideMarker("")
+	return strings.HasPrefix(trimmed, prefix) && strings.EqualFold(trimmed[len(prefix):], label)
+}
+
+// isOverrideEndMarker reports whether line closes the override block of the keybinding labelled label
+func isOverrideEndMarker(line, label string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, overrideEndPrefix) && strings.EqualFold(trimmed[len(overrideEndPrefix):], label)
+}
+
+// findOverrideBlock finds a keybinding's override: the marker line through its end
+// marker. end is exclusive.
+func findOverrideBlock(lines []string, label string) (start, end int, found bool) {
+	for i, line := range lines {
+		if isOverrideMarker(line, label) {
+			return i, overrideBlockEnd(lines, i, label), true
+		}
+	}
+	return -1, -1, false
+}
+
+// overrideBlockEnd returns the end (exclusive) of the override block whose marker is
+// lines[start]. Blocks written before end markers were added hold the unbinds
+// followed by at most one bind; the binds after it are the user's.
+func overrideBlockEnd(lines []string, start int, label string) int {
+	end := start + 1
+	for end < len(lines) && isBindOrUnbindLine(lines[end]) {
+		end++
+	}
+	if end < len(lines) && isOverrideEndMarker(lines[end], label) {
+		return end + 1
+	}
+
+	end = start + 1
+	for end < len(lines) {
+		if _, ok := parseUnbindLine(lines[end]); !ok {
+			break
+		}
+		end++
+	}
+	if end < len(lines) && isBindOrUnbindLine(lines[end]) {
+		end++
+	}
+	return end
+}
+
+// inOverrideBlock reports whether lines[index] belongs to an override block of the
+// keybinding labelled label
+func inOverrideBlock(lines []string, index int, label string) bool {
+	for i := index - 1; i >= 0; i-- {
+		if isOverrideMarker(lines[i], label) {
+			return index < overrideBlockEnd(lines, i, label)
+		}
+		if !isBindOrUnbindLine(lines[i]) {
+			return false
+		}
+	}
+	return false
+}
+
+// isBindOrUnbindLine reports whether line is an active bind or unbind directive
+func isBindOrUnbindLine(line string) bool {
+	key, _, ok := strings.Cut(strings.TrimSpace(hyprconf.StripComment(line)), "=")
+	if !ok {
+		return false
+	}
+	key = strings.TrimSpace(key)
+	return key == "unbind" || hyprconf.IsBindKeyword(key)
+}
+
+// removeLines returns lines without lines[start:end]
+func removeLines(lines []string, start, end int) []string {
+	return append(lines[:start:start], lines[end:]...)
+}
+
+// insertLines returns lines with inserted placed before index
+func insertLines(lines []string, index int, inserted ...string) []string {
+	newLines := make([]string, 0, len(lines)+len(inserted))
+	newLines = append(newLines, lines[:index]...)
+	newLines = append(newLines, inserted...)
+	return append(newLines, lines[index:]...)
+}
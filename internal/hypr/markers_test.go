@@ -0,0 +1,98 @@
+package hypr
+
+import "testing"
+
+func TestFindOverrideBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		wantStart int
+		wantEnd   int
+		wantBind  string // last bind of the block, "" for none
+	}{
+		{
+			name: "user binds after the block",
+			lines: []string{
+				"# OVERRIDES",
+				"# OVERRIDES: bind Firefox",
+				"unbind = SUPER, B",
+				"bindd = SUPER, F, Firefox, exec, firefox",
+				"# END OVERRIDES: bind Firefox",
+				"bind = SUPER, M, exec, spotify",
+				"bind = SUPER, N, exec, obsidian",
+			},
+			wantStart: 1, wantEnd: 5,
+			wantBind: "bindd = SUPER, F, Firefox, exec, firefox",
+		},
+		{
+			name: "unbind only",
+			lines: []string{
+				"# OVERRIDES",
+				"# OVERRIDES: bind Firefox",
+				"unbind = SUPER, B",
+				"# END OVERRIDES: bind Firefox",
+				"bind = SUPER, M, exec, spotify",
+			},
+			wantStart: 1, wantEnd: 4,
+		},
+		{
+			name: "block without an end marker keeps one bind",
+			lines: []string{
+				"# OVERRIDES",
+				"# OVERRIDES: bind Firefox",
+				"unbind = SUPER, B",
+				"bindd = SUPER, F, Firefox, exec, firefox",
+				"bind = SUPER, M, exec, spotify",
+			},
+			wantStart: 1, wantEnd: 4,
+			wantBind: "bindd = SUPER, F, Firefox, exec, firefox",
+		},
+		{
+			name: "end marker of another keybinding",
+			lines: []string{
+				"# OVERRIDES: bind Firefox",
+				"bindd = SUPER, F, Firefox, exec, firefox",
+				"# END OVERRIDES: bind Private",
+			},
+			wantStart: 0, wantEnd: 2,
+			wantBind: "bindd = SUPER, F, Firefox, exec, firefox",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, found := findOverrideBlock(tt.lines, "firefox")
+			if !found || start != tt.wantStart || end != tt.wantEnd {
+				t.Fatalf("findOverrideBlock = %d, %d, %v, want %d, %d", start, end, found, tt.wantStart, tt.wantEnd)
+			}
+			bind := lastBindInBlock(tt.lines[start:end])
+			got := ""
+			if bind != nil {
+				got = bind.String()
+			}
+			if got != tt.wantBind {
+				t.Errorf("lastBindInBlock = %q, want %q", got, tt.wantBind)
+			}
+			for i := range tt.lines {
+				want := i > start && i < end
+				if got := inOverrideBlock(tt.lines, i, "Firefox"); got != want {
+					t.Errorf("inOverrideBlock(line %d) = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResetKeepsUserBindsAfterOverride(t *testing.T) {
+	lines := []string{
+		"# OVERRIDES",
+		"# OVERRIDES: bind Firefox",
+		"bindd = SUPER, F, Firefox, exec, firefox",
+		"# END OVERRIDES: bind Firefox",
+		"bind = SUPER, M, exec, spotify",
+	}
+	start, end, _ := findOverrideBlock(lines, "Firefox")
+	got := removeLines(lines, start, end)
+	if len(got) != 2 || got[1] != "bind = SUPER, M, exec, spotify" {
+		t.Errorf("lines after removing the override = %q, want the user bind kept", got)
+	}
+}
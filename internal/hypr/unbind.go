@@ -0,0 +1,174 @@
+package hypr
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hyprconf"
+	"omarchy-tui/internal/logger"
+	"path/filepath"
+	"strings"
+)
+
+// Binds outside the user's bindings.conf (Omarchy's defaults under
+// ~/.local/share/omarchy) must not be edited. They are overridden from the
+// app's override block instead, with "unbind = MODS, KEY" ahead of the new bind:
+//
+//	# OVERRIDES: bind Firefox
+//	unbind = SUPER SHIFT, B                  <- the default bind being replaced
+//	unbind = SUPER, X                        <- a default bind the new combo takes over
+//	bindd = SUPER, X, Firefox, exec, firefox
+
+// unbindLine returns the unbind directive removing bind's key combination
+func unbindLine(bind *hyprconf.Bind) string {
+	return fmt.Sprintf("unbind = %s, %s", bind.Mods, bind.Key)
+}
+
+// parseUnbindLine returns the normalized combination of an unbind line
+func parseUnbindLine(line string) (combo string, ok bool) {
+	key, value, found := strings.Cut(strings.TrimSpace(hyprconf.StripComment(line)), "=")
+	if !found || strings.TrimSpace(key) != "unbind" {
+		return "", false
+	}
+	parts := strings.SplitN(value, ",", 3)
+	if len(parts) < 2 {
+		return "", false
+	}
+	return hyprconf.Combo(parts[0], parts[1]), true
+}
+
+// findExternalBind returns the bind labelled label defined outside the file at path
+// Unbinds are ignored so a default already overridden by us is still found.
+func findExternalBind(hyprConfig *hyprconf.Config, path, label string) *hyprconf.Bind {
+	if hyprConfig == nil {
+		return nil
+	}
+
+	var found *hyprconf.Bind
+	submap := ""
+	for _, d := range hyprConfig.Directives {
+		switch {
+		case d.Key == "submap":
+			submap = d.Value
+			if submap == "reset" {
+				submap = ""
+			}
+		case hyprconf.IsBindKeyword(d.Key) && submap == "" && !isSameFile(d.File, path):
+			bind, err := hyprconf.ParseBind(d)
+			if err != nil || !bind.HasDescription() || !strings.EqualFold(bind.Description, label) {
+				continue
+			}
+			// Keep the action as written (e.g. "exec, $terminal") so the override
+			// follows the variable; the keys stay resolved for unbind and matching
+			if source, err := hyprconf.ParseBindLine(d.Source); err == nil {
+				source.Mods, source.Key = bind.Mods, bind.Key
+				source.File, source.Line = bind.File, bind.Line
+				bind = source
+			}
+			// The last definition wins
+			found = bind
+		}
+	}
+	return found
+}
+
+// externalUnbinds returns the unbind lines needed so that bind takes over its combination
+// from binds defined outside the file at path
+func externalUnbinds(hyprConfig *hyprconf.Config, path string, conflicts []Conflict) []string {
+	seen := make(map[string]bool)
+	var unbinds []string
+	for _, conflict := range conflicts {
+		if isSameFile(conflict.Bind.File, path) || seen[conflict.Bind.Combo()] {
+			continue
+		}
+		seen[conflict.Bind.Combo()] = true
+		warnIfParsedAfter(hyprConfig, conflict.Bind, path)
+		unbinds = append(unbinds, unbindLine(conflict.Bind))
+	}
+	return unbinds
+}
+
+// UnbindWarnings returns a warning for each bind ReassignKeybinding would unbind from
+// bindings.conf that is sourced after it, where the unbind can't reach: the default
+// bind of app's keybinding labelled label and the conflicts defined in other files
+func UnbindWarnings(app *config.Application, label string, conflicts []Conflict) []string {
+	if label == "" {
+		label = app.Name
+	}
+	hyprPath, err := hyprconf.ExpandPath("~/.config/hypr/bindings.conf")
+	if err != nil {
+		return nil
+	}
+	hyprConfig := loadHyprConfig()
+
+	var binds []*hyprconf.Bind
+	if external := findExternalBind(hyprConfig, hyprPath, label); external != nil {
+		binds = append(binds, external)
+	}
+	for _, conflict := range conflicts {
+		if !isSameFile(conflict.Bind.File, hyprPath) {
+			binds = append(binds, conflict.Bind)
+		}
+	}
+
+	var warnings []string
+	for _, bind := range binds {
+		if warning := parsedAfterWarning(hyprConfig, bind, hyprPath); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// warnIfParsedAfter logs when bind is parsed after the file at path (see parsedAfterWarning)
+func warnIfParsedAfter(hyprConfig *hyprconf.Config, bind *hyprconf.Bind, path string) {
+	if warning := parsedAfterWarning(hyprConfig, bind, path); warning != "" {
+		logger.Log("warnIfParsedAfter: %s", warning)
+	}
+}
+
+// parsedAfterWarning describes bind when it is parsed after the file at path, since an
+// unbind in path can only remove binds defined before it; "" otherwise
+func parsedAfterWarning(hyprConfig *hyprconf.Config, bind *hyprconf.Bind, path string) string {
+	if hyprConfig == nil {
+		return ""
+	}
+	lastInPath, bindIndex := -1, -1
+	for i, d := range hyprConfig.Directives {
+		if isSameFile(d.File, path) {
+			lastInPath = i
+		}
+		if d.File == bind.File && d.Line == bind.Line {
+			bindIndex = i
+		}
+	}
+	if bindIndex <= lastInPath || lastInPath < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s) is loaded after %s, so it can't be unbound and stays active",
+		Conflict{Bind: bind}.Location(), bind.Action(), filepath.Base(path))
+}
+
+// originalUnbinds returns the unbind lines of an override block that remove the app's
+// default bind, i.e. every unbind except the one for the block's own combination
+func originalUnbinds(block []string, bind *hyprconf.Bind) []string {
+	var unbinds []string
+	for _, line := range block {
+		combo, ok := parseUnbindLine(line)
+		if !ok || (bind != nil && combo == bind.Combo()) {
+			continue
+		}
+		unbinds = append(unbinds, strings.TrimSpace(line))
+	}
+	return unbinds
+}
+
+// loadHyprConfig parses the Hyprland config for unbind decisions; failures are logged,
+// not fatal, since the user's bindings.conf can still be updated
+func loadHyprConfig() *hyprconf.Config {
+	hyprConfig, err := config.LoadHyprConfig()
+	if err != nil {
+		logger.Log("loadHyprConfig: Failed to parse Hyprland config: %v", err)
+		return nil
+	}
+	return hyprConfig
+}
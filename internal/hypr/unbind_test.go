@@ -0,0 +1,151 @@
+package hypr
+
+import (
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hyprconf"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeHyprConfig writes files into a temporary directory and parses hyprland.conf from it
+func writeHyprConfig(t *testing.T, files map[string]string) (string, *hyprconf.Config) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hyprConfig, err := hyprconf.ParseFile(filepath.Join(dir, "hyprland.conf"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	return dir, hyprConfig
+}
+
+func TestFindExternalBindKeepsVariables(t *testing.T) {
+	dir, hyprConfig := writeHyprConfig(t, map[string]string{
+		"hyprland.conf": "$terminal = uwsm app -- alacritty\n$mainMod = SUPER\nsource = defaults.conf\nsource = bindings.conf\n",
+		"defaults.conf": "bindd = $mainMod, RETURN, Terminal, exec, $terminal   # default\n",
+		"bindings.conf": "# OVERRIDES\n",
+	})
+
+	bind := findExternalBind(hyprConfig, filepath.Join(dir, "bindings.conf"), "terminal")
+	if bind == nil {
+		t.Fatal("external bind not found")
+	}
+	if bind.Args != "$terminal" {
+		t.Errorf("Args = %q, want the variable as written", bind.Args)
+	}
+	if bind.Mods != "SUPER" || bind.Key != "RETURN" {
+		t.Errorf("keys = %q, %q, want them resolved", bind.Mods, bind.Key)
+	}
+	if bind.File != filepath.Join(dir, "defaults.conf") || bind.Line != 1 {
+		t.Errorf("location = %s:%d, want defaults.conf:1", bind.File, bind.Line)
+	}
+	if got := unbindLine(bind); got != "unbind = SUPER, RETURN" {
+		t.Errorf("unbindLine = %q", got)
+	}
+
+	bind.Key = "T"
+	if got, want := bind.String(), "bindd = SUPER, T, Terminal, exec, $terminal"; got != want {
+		t.Errorf("override = %q, want %q", got, want)
+	}
+}
+
+func TestParsedAfterWarning(t *testing.T) {
+	dir, hyprConfig := writeHyprConfig(t, map[string]string{
+		"hyprland.conf": "source = early.conf\nsource = bindings.conf\nsource = late.conf\n",
+		"early.conf":    "bindd = SUPER, B, Browser, exec, firefox\n",
+		"bindings.conf": "bindd = SUPER, F, Files, exec, nautilus\n",
+		"late.conf":     "bindd = SUPER, M, Music, exec, spotify\n",
+	})
+	path := filepath.Join(dir, "bindings.conf")
+
+	if warning := parsedAfterWarning(hyprConfig, findExternalBind(hyprConfig, path, "Browser"), path); warning != "" {
+		t.Errorf("bind sourced before bindings.conf warned: %s", warning)
+	}
+	warning := parsedAfterWarning(hyprConfig, findExternalBind(hyprConfig, path, "Music"), path)
+	if !strings.Contains(warning, "late.conf:1") || !strings.Contains(warning, "bindings.conf") {
+		t.Errorf("warning = %q, want the late bind and bindings.conf named", warning)
+	}
+	if warning := parsedAfterWarning(nil, findExternalBind(hyprConfig, path, "Music"), path); warning != "" {
+		t.Errorf("warning without a parsed config = %q", warning)
+	}
+}
+
+func TestFindOriginalBinds(t *testing.T) {
+	content := strings.Join([]string{
+		"$browser = uwsm app -- firefox",
+		"bind = SUPER, B, exec, $browser",
+		"bindd = SUPER SHIFT, B, Private, exec, firefox --private-window",
+		"bindd = SUPER, M, Music, exec, spotify",
+		"bindd = SUPER, W, Firefox, exec, omarchy-launch-webapp https://example.com",
+		"# OVERRIDES",
+		"# OVERRIDES: bind Firefox",
+		"bindd = SUPER, F, Firefox, exec, firefox",
+	}, "\n") + "\n"
+	dir, hyprConfig := writeHyprConfig(t, map[string]string{"hyprland.conf": content})
+	path := filepath.Join(dir, "hyprland.conf")
+	lines, err := readLines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &config.Application{Name: "Firefox", PackageName: "firefox"}
+
+	tests := []struct {
+		label string
+		want  []int // line numbers
+	}{
+		// By command, undescribed, and by label; never the override block's bind
+		{"", []int{2, 5}},
+		{"firefox", []int{2, 5}},
+		{"Private", []int{3}},
+		{"Music", nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, bind := range findOriginalBinds(hyprConfig, lines, path, app, tt.label) {
+			got = append(got, bind.Line)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("findOriginalBinds(%q) lines = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+
+	// Returned as written, so rewriting keeps the variable
+	originals := findOriginalBinds(hyprConfig, lines, path, app, "")
+	if originals[0].Args != "$browser" || originals[0].String() != lines[1] {
+		t.Errorf("original = %q (args %q), want the line as written", originals[0], originals[0].Args)
+	}
+
+	// A line changed since parsing is not touched
+	lines[1] = "bind = SUPER, B, exec, chromium"
+	if got := findOriginalBinds(hyprConfig, lines, path, app, ""); len(got) != 1 || got[0].Line != 5 {
+		t.Errorf("findOriginalBinds after a change = %v, want only line 5", got)
+	}
+}
+
+func TestIsAppBind(t *testing.T) {
+	_, hyprConfig := writeHyprConfig(t, map[string]string{
+		"hyprland.conf": "$browser = uwsm app -- firefox\n",
+	})
+	app := &config.Application{Name: "Firefox", PackageName: "firefox"}
+	bind, err := hyprconf.ParseBindLine("bind = SUPER, B, exec, $browser")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isAppBind(hyprConfig, app, bind, "") {
+		t.Error("bind running the app through a variable should match")
+	}
+	if isAppBind(nil, app, bind, "") {
+		t.Error("unresolved variable should not match")
+	}
+	if bind.Args != "$browser" {
+		t.Errorf("matching changed the bind: args = %q", bind.Args)
+	}
+}
@@ -0,0 +1,125 @@
+package hypr
+
+import (
+	"fmt"
+	"omarchy-tui/internal/fsutil"
+	"omarchy-tui/internal/hyprconf"
+	"omarchy-tui/internal/logger"
+	"os"
+	"strings"
+)
+
+// uwsmPrefix is the launcher prefix Omarchy uses for variable commands
+const uwsmPrefix = "uwsm app -- "
+
+// parseVariableLine parses a "$name = value" line; the value has its comment stripped
+// and "##" unescaped
+func parseVariableLine(line string) (name, value string, ok bool) {
+	trimmed := strings.TrimSpace(hyprconf.StripComment(line))
+	if !strings.HasPrefix(trimmed, "$") {
+		return "", "", false
+	}
+	parts := strings.SplitN(trimmed[1:], "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// variableOverrideMarker returns the comment placed above a variable override
+// It differs from the bind "# OVERRIDES" section marker so binds are never inserted there.
+func variableOverrideMarker(name string) string {
+	return "# OVERRIDES: $" + name
+}
+
+// variableLabel is the label a variable's disabled definition is recorded under
+func variableLabel(name string) string {
+	return "$" + name
+}
+
+// SetVariable sets a Hyprland variable (e.g. $terminal) in bindings.conf to command.
+// Like AddKeybinding, the original definition is disabled (see disableLine) and the
+// new one is added under an "# OVERRIDES: $name" marker. Hyprland substitutes variables
+// while parsing, so the override goes right after the original definition rather than
+// at the end, keeping it ahead of the binds using it; a variable bindings.conf doesn't
+// define is an error. A "uwsm app -- " prefix on the original value is preserved.
+// The caller reloads Hyprland.
+func SetVariable(name, command string) error {
+	name = strings.TrimPrefix(name, "$")
+	if name == "" {
+		return fmt.Errorf("empty variable name")
+	}
+
+	hyprPath, err := hyprconf.ExpandPath("~/.config/hypr/bindings.conf")
+	if err != nil {
+		return fmt.Errorf("failed to expand hypr config path: %w", err)
+	}
+
+	unlock, err := fsutil.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Check if file exists
+	if _, err := os.Stat(hyprPath); os.IsNotExist(err) {
+		return fmt.Errorf("bindings.conf not found at %s", hyprPath)
+	}
+
+	lines, err := readLines(hyprPath)
+	if err != nil {
+		return fmt.Errorf("failed to read bindings.conf: %w", err)
+	}
+
+	lines, changed, err := setVariable(lines, name, command)
+	if err != nil || !changed {
+		return err
+	}
+
+	if err := writeLines(hyprPath, lines); err != nil {
+		return fmt.Errorf("failed to write bindings.conf: %w", err)
+	}
+	return nil
+}
+
+// setVariable returns lines with $name set to command, and whether anything changed
+func setVariable(lines []string, name, command string) ([]string, bool, error) {
+	// Find the active definition (the last one wins in Hyprland)
+	lineIndex := -1
+	for i, line := range lines {
+		if varName, _, ok := parseVariableLine(line); ok && varName == name {
+			lineIndex = i
+		}
+	}
+	if lineIndex < 0 {
+		logger.Log("SetVariable: No definition of $%s found", name)
+		return lines, false, fmt.Errorf("$%s is not defined in bindings.conf", name)
+	}
+
+	value := command
+	_, original, _ := parseVariableLine(lines[lineIndex])
+	if strings.HasPrefix(original, uwsmPrefix) && !strings.HasPrefix(command, uwsmPrefix) {
+		value = uwsmPrefix + command
+	}
+	if original == value {
+		logger.Log("SetVariable: $%s already set to '%s'", name, value)
+		return lines, false, nil
+	}
+	newLine := fmt.Sprintf("$%s = %s", name, hyprconf.EscapeComment(value))
+	marker := variableOverrideMarker(name)
+
+	if lineIndex > 0 && strings.TrimSpace(lines[lineIndex-1]) == marker {
+		// Our own earlier override: update it in place
+		lines[lineIndex] = lineIndent(lines[lineIndex]) + newLine
+		logger.Log("SetVariable: Updating existing override of $%s at index %d", name, lineIndex)
+	} else {
+		// Disable the original line and add the override below it
+		indent := lineIndent(lines[lineIndex])
+		disabled := disableLine(lines[lineIndex], variableLabel(name))
+		lines = insertLines(removeLines(lines, lineIndex, lineIndex+1), lineIndex, disabled, indent+marker, indent+newLine)
+		logger.Log("SetVariable: Disabled original definition of $%s at index %d", name, lineIndex)
+	}
+
+	logger.Log("SetVariable: Set $%s = %s", name, value)
+	return lines, true, nil
+}
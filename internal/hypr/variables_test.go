@@ -0,0 +1,102 @@
+package hypr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetVariable(t *testing.T) {
+	tests := []struct {
+		name        string
+		variable    string
+		lines       []string
+		command     string
+		want        []string
+		wantChanged bool
+		wantErr     bool
+	}{
+		{
+			name:    "replaces the value",
+			lines:   []string{"$terminal = alacritty", "bind = SUPER, RETURN, exec, $terminal"},
+			command: "foot",
+			want: []string{
+				"# OMARCHY-TUI DISABLED [$terminal]: $terminal = alacritty",
+				"# OVERRIDES: $terminal",
+				"$terminal = foot",
+				"bind = SUPER, RETURN, exec, $terminal",
+			},
+			wantChanged: true,
+		},
+		{
+			name:     "keeps the uwsm prefix",
+			variable: "browser",
+			lines:    []string{"  $browser = uwsm app -- chromium --new-window"},
+			command:  "firefox",
+			want: []string{
+				"  # OMARCHY-TUI DISABLED [$browser]: $browser = uwsm app -- chromium --new-window",
+				"  # OVERRIDES: $browser",
+				"  $browser = uwsm app -- firefox",
+			},
+			wantChanged: true,
+		},
+		{
+			name: "updates its own override in place",
+			lines: []string{
+				"# OMARCHY-TUI DISABLED [$terminal]: $terminal = alacritty",
+				"# OVERRIDES: $terminal",
+				"$terminal = foot",
+			},
+			command: "kitty",
+			want: []string{
+				"# OMARCHY-TUI DISABLED [$terminal]: $terminal = alacritty",
+				"# OVERRIDES: $terminal",
+				"$terminal = kitty",
+			},
+			wantChanged: true,
+		},
+		{
+			name:     "escapes # in the value",
+			variable: "browser",
+			lines:    []string{"$browser = chromium # the default"},
+			command:  "omarchy-launch-webapp https://example.com/#inbox",
+			want: []string{
+				"# OMARCHY-TUI DISABLED [$browser]: $browser = chromium # the default",
+				"# OVERRIDES: $browser",
+				"$browser = omarchy-launch-webapp https://example.com/##inbox",
+			},
+			wantChanged: true,
+		},
+		{
+			name:    "same value is left alone",
+			lines:   []string{"$terminal = uwsm app -- alacritty"},
+			command: "alacritty",
+			want:    []string{"$terminal = uwsm app -- alacritty"},
+		},
+		{
+			name:    "missing definition",
+			lines:   []string{"bind = SUPER, RETURN, exec, alacritty"},
+			command: "foot",
+			want:    []string{"bind = SUPER, RETURN, exec, alacritty"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variable := tt.variable
+			if variable == "" {
+				variable = "terminal"
+			}
+			got, changed, err := setVariable(append([]string(nil), tt.lines...), variable, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setVariable error = %v, want error %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
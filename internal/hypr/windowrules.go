@@ -0,0 +1,331 @@
+package hypr
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/fsutil"
+	"omarchy-tui/internal/hyprconf"
+	"omarchy-tui/internal/logger"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// windowRulesPath is the managed file holding the window rules written by omarchy-tui
+// It is sourced at the end of hyprland.conf so its rules come after (and win over)
+// Omarchy's defaults. Rules in other files are never edited.
+const windowRulesPath = "~/.config/hypr/windowrules-overrides.conf"
+
+// windowRulesHeader starts a newly created managed window rules file
+const windowRulesHeader = "# Window rules managed by omarchy-tui (Window rules action of an app)"
+
+// WindowRule is a windowrule/windowrulev2 directive
+// Example: windowrulev2 = opacity 0.9 0.8, class:^(firefox)$
+type WindowRule struct {
+	Keyword string // "windowrule" or "windowrulev2"
+	Rule    string // the effect, e.g. "float" or "size 1200 800"
+	Match   string // the match fields, e.g. "class:^(firefox)$"
+	File    string
+	Line    int
+}
+
+// parseWindowRule parses a window rule directive (variables already resolved)
+func parseWindowRule(d hyprconf.Directive) (WindowRule, bool) {
+	if d.Key != "windowrule" && d.Key != "windowrulev2" {
+		return WindowRule{}, false
+	}
+	rule, match, ok := strings.Cut(d.Value, ",")
+	if !ok {
+		return WindowRule{}, false
+	}
+	return WindowRule{
+		Keyword: d.Key,
+		Rule:    strings.TrimSpace(rule),
+		Match:   strings.TrimSpace(match),
+		File:    d.File,
+		Line:    d.Line,
+	}, true
+}
+
+// parseWindowRuleLine parses a raw config line of the managed file
+func parseWindowRuleLine(line string) (WindowRule, bool) {
+	key, value, ok := strings.Cut(strings.TrimSpace(hyprconf.StripComment(line)), "=")
+	if !ok {
+		return WindowRule{}, false
+	}
+	return parseWindowRule(hyprconf.Directive{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+}
+
+// String returns the rule as a config line
+func (r WindowRule) String() string {
+	return fmt.Sprintf("%s = %s, %s", r.Keyword, r.Rule, r.Match)
+}
+
+// Location returns "file:line" with the home directory shortened to ~
+func (r WindowRule) Location() string {
+	return Conflict{Bind: &hyprconf.Bind{File: r.File, Line: r.Line}}.Location()
+}
+
+// Managed reports whether the rule lives in the managed file and can be edited
+func (r WindowRule) Managed() bool {
+	path, err := hyprconf.ExpandPath(windowRulesPath)
+	return err == nil && isSameFile(r.File, path)
+}
+
+// windowRuleFields are the match fields of windowrulev2 (lowercase)
+var windowRuleFields = map[string]bool{
+	"class": true, "title": true, "initialclass": true, "initialtitle": true, "tag": true,
+	"xwayland": true, "floating": true, "fullscreen": true, "pinned": true, "focus": true,
+	"group": true, "modal": true, "fullscreenstate": true, "workspace": true,
+	"onworkspace": true, "content": true, "xdgtag": true,
+}
+
+// matchFields splits a rule's match into its "name:value" fields
+// A comma only separates fields when a known field name follows it, so commas inside
+// a regex (e.g. "class:^(a{1,2})$") stay part of the value. ok is false if the match
+// doesn't start with a field (the original windowrule syntax).
+func matchFields(match string) (fields []string, ok bool) {
+	for _, part := range strings.Split(match, ",") {
+		name, _, isField := strings.Cut(strings.TrimSpace(part), ":")
+		if isField && windowRuleFields[strings.ToLower(strings.TrimSpace(name))] {
+			fields = append(fields, strings.TrimSpace(part))
+			continue
+		}
+		if len(fields) == 0 {
+			return nil, false
+		}
+		fields[len(fields)-1] += "," + part
+	}
+	return fields, len(fields) > 0
+}
+
+// ClassPattern returns the class regex the rule matches, "" if it doesn't match on class
+// windowrulev2 (and windowrule since Hyprland 0.48) use "class:REGEX" fields; the
+// original windowrule syntax has a bare regex matched against the class.
+func (r WindowRule) ClassPattern() string {
+	fields, ok := matchFields(r.Match)
+	if !ok {
+		if r.Keyword == "windowrulev2" {
+			return ""
+		}
+		return strings.TrimSpace(r.Match)
+	}
+	for _, field := range fields {
+		name, value, _ := strings.Cut(field, ":")
+		if strings.TrimSpace(name) == "class" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// classMatch returns the match fields of a new rule for windows of exactly class
+func classMatch(class string) string {
+	return fmt.Sprintf("class:^(%s)$", regexp.QuoteMeta(class))
+}
+
+// MatchesClass reports whether the rule applies to windows of class
+// Hyprland matches the whole class against the regex; an invalid regex is
+// compared literally.
+func (r WindowRule) MatchesClass(class string) bool {
+	pattern := r.ClassPattern()
+	if pattern == "" || class == "" {
+		return false
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return pattern == class
+	}
+	return re.MatchString(class)
+}
+
+// LoadWindowRules returns every window rule in the Hyprland config, in parse order
+func LoadWindowRules() ([]WindowRule, error) {
+	hyprConfig, err := config.LoadHyprConfig()
+	if err != nil {
+		return nil, err
+	}
+	if hyprConfig == nil {
+		return nil, nil
+	}
+
+	var rules []WindowRule
+	for _, d := range hyprConfig.Directives {
+		if rule, ok := parseWindowRule(d); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// WindowRulesFor returns the rules that apply to windows of class
+func WindowRulesFor(rules []WindowRule, class string) []WindowRule {
+	var matching []WindowRule
+	for _, rule := range rules {
+		if rule.MatchesClass(class) {
+			matching = append(matching, rule)
+		}
+	}
+	return matching
+}
+
+// AddWindowRule adds "rule" for windows of class to the managed file
+// Hyprland applies it after the rules of the files sourced before, so it also serves
+// to override a rule defined elsewhere.
+func AddWindowRule(class, rule string) error {
+	return editWindowRules(func(lines []string, keyword string) ([]string, error) {
+		return addWindowRule(lines, keyword, class, rule), nil
+	})
+}
+
+// addWindowRule returns lines with a rule for windows of class appended
+func addWindowRule(lines []string, keyword, class, rule string) []string {
+	newRule := WindowRule{Keyword: keyword, Rule: rule, Match: classMatch(class)}
+	logger.Log("AddWindowRule: Adding %s", newRule)
+	return append(lines, newRule.String())
+}
+
+// UpdateWindowRule changes the effect of old to rule
+// Only rules in the managed file can be edited; a rule defined in another file is
+// overridden by adding a rule instead (see AddWindowRule).
+func UpdateWindowRule(old WindowRule, rule string) error {
+	if !old.Managed() {
+		return fmt.Errorf("rule is defined in %s, only rules in %s can be edited", old.Location(), windowRulesPath)
+	}
+	return editWindowRules(func(lines []string, keyword string) ([]string, error) {
+		return updateWindowRule(lines, old, rule)
+	})
+}
+
+// updateWindowRule returns lines with the effect of old, read from them, changed to rule
+func updateWindowRule(lines []string, old WindowRule, rule string) ([]string, error) {
+	index, err := findWindowRuleLine(lines, old)
+	if err != nil {
+		return nil, err
+	}
+	updated := old
+	updated.Rule = rule
+	lines[index] = updated.String()
+	logger.Log("UpdateWindowRule: Updated line %d to %s", old.Line, updated)
+	return lines, nil
+}
+
+// RemoveWindowRule deletes old from the managed file
+func RemoveWindowRule(old WindowRule) error {
+	if !old.Managed() {
+		return fmt.Errorf("rule is defined in %s, only rules in %s can be removed", old.Location(), windowRulesPath)
+	}
+	return editWindowRules(func(lines []string, keyword string) ([]string, error) {
+		return removeWindowRule(lines, old)
+	})
+}
+
+// removeWindowRule returns lines without old
+func removeWindowRule(lines []string, old WindowRule) ([]string, error) {
+	index, err := findWindowRuleLine(lines, old)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log("RemoveWindowRule: Removed line %d: %s", old.Line, old)
+	return removeLines(lines, index, index+1), nil
+}
+
+// findWindowRuleLine returns the index of rule in the managed file's lines, making sure
+// the line hasn't changed since it was parsed
+func findWindowRuleLine(lines []string, rule WindowRule) (int, error) {
+	index := rule.Line - 1
+	if index >= 0 && index < len(lines) {
+		if current, ok := parseWindowRuleLine(lines[index]); ok && current.Rule == rule.Rule && current.Match == rule.Match {
+			return index, nil
+		}
+	}
+	return -1, fmt.Errorf("rule at %s changed since it was read, reload and try again", rule.Location())
+}
+
+// editWindowRules applies edit to the lines of the managed file under the config lock,
+// then writes it and makes sure hyprland.conf sources it; both writes are rolled back
+// together on failure
+func editWindowRules(edit func(lines []string, keyword string) ([]string, error)) error {
+	rulesPath, err := hyprconf.ExpandPath(windowRulesPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand window rules path: %w", err)
+	}
+	hyprPath, err := hyprconf.ExpandPath(hyprconf.DefaultConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to expand hyprland.conf path: %w", err)
+	}
+
+	unlock, err := fsutil.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lines, err := readLines(rulesPath)
+	if os.IsNotExist(err) {
+		lines = []string{windowRulesHeader, ""}
+	} else if err != nil {
+		return fmt.Errorf("failed to read window rules: %w", err)
+	}
+
+	lines, err = edit(lines, windowRuleKeyword(loadHyprConfig()))
+	if err != nil {
+		return err
+	}
+
+	tx := fsutil.NewTransaction()
+	if err := tx.WriteFile(rulesPath, joinLines(lines), 0644); err != nil {
+		return fmt.Errorf("failed to write window rules: %w", err)
+	}
+	if err := ensureSourced(tx, hyprPath, rulesPath); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logger.Log("editWindowRules: Rollback failed: %v", rbErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureSourced appends a source= line for path to hyprland.conf unless it already
+// sources it (directly or through a glob)
+func ensureSourced(tx *fsutil.Transaction, hyprPath, path string) error {
+	if hyprConfig := loadHyprConfig(); hyprConfig != nil {
+		for _, file := range hyprConfig.Files {
+			if isSameFile(file, path) {
+				return nil
+			}
+		}
+		// A source= line for a file that didn't exist yet isn't in Files
+		for _, d := range hyprConfig.Directives {
+			if sourced, err := hyprconf.ExpandPath(d.Value); d.Key == "source" && err == nil && sourced == path {
+				return nil
+			}
+		}
+	}
+
+	lines, err := readLines(hyprPath)
+	if err != nil {
+		return fmt.Errorf("failed to read hyprland.conf: %w", err)
+	}
+	lines = append(lines, "", "# Window rules managed by omarchy-tui", "source = "+windowRulesPath)
+	if err := tx.WriteFile(hyprPath, joinLines(lines), 0644); err != nil {
+		return fmt.Errorf("failed to write hyprland.conf: %w", err)
+	}
+	logger.Log("ensureSourced: Added source of %s to %s", windowRulesPath, hyprPath)
+	return nil
+}
+
+// windowRuleKeyword returns the keyword new rules are written with
+// Hyprland 0.48 renamed windowrulev2 to windowrule; the config's own usage tells which
+// version it targets, defaulting to windowrulev2.
+func windowRuleKeyword(hyprConfig *hyprconf.Config) string {
+	if hyprConfig == nil {
+		return "windowrulev2"
+	}
+	for _, d := range hyprConfig.Directives {
+		if rule, ok := parseWindowRule(d); ok && rule.Keyword == "windowrule" && strings.Contains(rule.Match, "class:") {
+			return "windowrule"
+		}
+	}
+	return "windowrulev2"
+}
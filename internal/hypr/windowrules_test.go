@@ -0,0 +1,119 @@
+package hypr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClassPattern(t *testing.T) {
+	tests := []struct {
+		keyword string
+		match   string
+		want    string
+	}{
+		{"windowrulev2", "class:^(firefox)$", "^(firefox)$"},
+		{"windowrulev2", "title:^(Picture-in-Picture)$, class:^(firefox)$", "^(firefox)$"},
+		{"windowrulev2", "class:^(firefox)$, floating:1", "^(firefox)$"},
+		{"windowrulev2", "initialClass:^(firefox)$", ""},
+		{"windowrulev2", "title:^(Save as)$", ""},
+		// Commas inside the regex belong to it
+		{"windowrulev2", "class:^(org\\.app\\.[a-z]{2,8})$, title:^(Main)$", "^(org\\.app\\.[a-z]{2,8})$"},
+		{"windowrulev2", "class:^(one,two)$", "^(one,two)$"},
+		{"windowrule", "class:^(Alacritty|foot)$", "^(Alacritty|foot)$"},
+		// The original windowrule syntax: a bare class regex
+		{"windowrule", "^(pavucontrol)$", "^(pavucontrol)$"},
+		{"windowrule", "^(a{1,2})$", "^(a{1,2})$"},
+		{"windowrule", "title:^(Open File)$", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyword+" "+tt.match, func(t *testing.T) {
+			rule := WindowRule{Keyword: tt.keyword, Rule: "float", Match: tt.match}
+			if got := rule.ClassPattern(); got != tt.want {
+				t.Errorf("ClassPattern = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassMatch(t *testing.T) {
+	tests := []struct {
+		class string
+		match string
+		other string // a class the rule must not match
+	}{
+		{"firefox", "class:^(firefox)$", "firefox-developer-edition"},
+		{"org.gnome.Nautilus", `class:^(org\.gnome\.Nautilus)$`, "orgXgnomeXNautilus"},
+		{"chrome-chatgpt.com__-Default", `class:^(chrome-chatgpt\.com__-Default)$`, "chrome-chatgpt.com__-Profile"},
+		{"a+b (c)", `class:^(a\+b \(c\))$`, "aab (c)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			rule := WindowRule{Keyword: "windowrulev2", Rule: "float", Match: classMatch(tt.class)}
+			if rule.Match != tt.match {
+				t.Errorf("classMatch = %q, want %q", rule.Match, tt.match)
+			}
+			if !rule.MatchesClass(tt.class) {
+				t.Errorf("rule %s doesn't match its own class", rule)
+			}
+			if rule.MatchesClass(tt.other) {
+				t.Errorf("rule %s matches %q", rule, tt.other)
+			}
+		})
+	}
+}
+
+func TestManagedWindowRuleEdits(t *testing.T) {
+	lines := []string{windowRulesHeader, "", "windowrulev2 = float, class:^(firefox)$"}
+
+	lines = addWindowRule(lines, "windowrulev2", "org.gnome.Nautilus", "size 1200 800")
+	want := []string{
+		windowRulesHeader,
+		"",
+		"windowrulev2 = float, class:^(firefox)$",
+		`windowrulev2 = size 1200 800, class:^(org\.gnome\.Nautilus)$`,
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("after add:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	firefox, _ := parseWindowRuleLine(lines[2])
+	firefox.Line = 3
+	lines, err := updateWindowRule(lines, firefox, "opacity 0.9 0.8")
+	if err != nil {
+		t.Fatalf("updateWindowRule: %v", err)
+	}
+	if lines[2] != "windowrulev2 = opacity 0.9 0.8, class:^(firefox)$" {
+		t.Errorf("after update: line 3 = %q", lines[2])
+	}
+
+	// firefox still has the effect it was read with, and the line has changed since
+	if _, err := removeWindowRule(lines, firefox); err == nil {
+		t.Error("removeWindowRule of a changed line succeeded")
+	}
+	firefox.Rule = "opacity 0.9 0.8"
+	lines, err = removeWindowRule(lines, firefox)
+	if err != nil {
+		t.Fatalf("removeWindowRule: %v", err)
+	}
+	want = []string{windowRulesHeader, "", `windowrulev2 = size 1200 800, class:^(org\.gnome\.Nautilus)$`}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("after remove:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnmanagedWindowRuleIsReadOnly(t *testing.T) {
+	rule := WindowRule{
+		Keyword: "windowrulev2",
+		Rule:    "float",
+		Match:   "class:^(pavucontrol)$",
+		File:    "/usr/share/omarchy/default/hypr/windows.conf",
+		Line:    12,
+	}
+	if err := UpdateWindowRule(rule, "tile"); err == nil || !strings.Contains(err.Error(), "windows.conf:12") {
+		t.Errorf("UpdateWindowRule error = %v, want the rule's other file reported", err)
+	}
+	if err := RemoveWindowRule(rule); err == nil {
+		t.Error("RemoveWindowRule of an unmanaged rule succeeded")
+	}
+}
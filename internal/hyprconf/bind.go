@@ -0,0 +1,352 @@
+package hyprconf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// validBindFlags lists the flag letters Hyprland accepts after "bind":
+// l locked, r release, c click, g drag, o long press, e repeat, n non-consuming,
+// m mouse, t transparent, i ignore mods, s separate, d description,
+// p bypass inhibitors, u submap universal
+const validBindFlags = "lrcgoenmtisdpu"
+
+// BindFlags is the set of flag letters of a bind keyword, e.g. "el" for bindel
+type BindFlags string
+
+// Has reports whether flag is set
+func (f BindFlags) Has(flag rune) bool {
+	return strings.ContainsRune(string(f), flag)
+}
+
+// With returns the flags with flag added (keeping the original letter order)
+func (f BindFlags) With(flag rune) BindFlags {
+	if f.Has(flag) {
+		return f
+	}
+	return f + BindFlags(flag)
+}
+
+// Without returns the flags with flag removed
+func (f BindFlags) Without(flag rune) BindFlags {
+	return BindFlags(strings.ReplaceAll(string(f), string(flag), ""))
+}
+
+// Equal reports whether both sets contain the same flags, regardless of order
+func (f BindFlags) Equal(other BindFlags) bool {
+	return sortedFlags(f) == sortedFlags(other)
+}
+
+// sortedFlags returns the flag letters sorted, for order-insensitive comparison
+func sortedFlags(f BindFlags) string {
+	letters := strings.Split(string(f), "")
+	sort.Strings(letters)
+	return strings.Join(letters, "")
+}
+
+// ParseBindKeyword parses a bind keyword such as "bind", "bindd" or "bindel"
+// ok is false for other keywords (including "unbind") or unknown flag letters.
+func ParseBindKeyword(keyword string) (flags BindFlags, ok bool) {
+	keyword = strings.TrimSpace(keyword)
+	if !strings.HasPrefix(keyword, "bind") {
+		return "", false
+	}
+	letters := keyword[len("bind"):]
+	seen := make(map[rune]bool)
+	for _, r := range letters {
+		if !strings.ContainsRune(validBindFlags, r) || seen[r] {
+			return "", false
+		}
+		seen[r] = true
+	}
+	return BindFlags(letters), true
+}
+
+// IsBindKeyword reports whether keyword is any bind flavor
+func IsBindKeyword(keyword string) bool {
+	_, ok := ParseBindKeyword(keyword)
+	return ok
+}
+
+// Bind is a parsed bind directive
+type Bind struct {
+	Flags       BindFlags
+	Mods        string // modifiers as written, e.g. "SUPER SHIFT"
+	Key         string
+	Description string // only set with the d flag
+	Dispatcher  string
+	Args        string // may be empty, e.g. for bindm
+	File        string // where the bind was read from ("" if not from a file)
+	Line        int
+	Raw         string // the line as written, re-emitted by String while the bind is unchanged
+
+	original bindFields
+}
+
+// bindFields are the editable parts of a bind, used to detect changes
+type bindFields struct {
+	flags                                    BindFlags
+	mods, key, description, dispatcher, args string
+}
+
+// fields returns the current editable parts of the bind
+func (b *Bind) fields() bindFields {
+	return bindFields{b.Flags, b.Mods, b.Key, b.Description, b.Dispatcher, b.Args}
+}
+
+// Keyword returns the bind keyword including its flags, e.g. "bindd"
+func (b *Bind) Keyword() string {
+	return "bind" + string(b.Flags)
+}
+
+// HasDescription reports whether the bind carries a description (d flag)
+func (b *Bind) HasDescription() bool {
+	return b.Flags.Has('d')
+}
+
+// IsExec reports whether the bind runs a command
+func (b *Bind) IsExec() bool {
+	return b.Dispatcher == "exec" || b.Dispatcher == "execr"
+}
+
+// Action describes what the bind does, e.g. "exec firefox" or "killactive"
+func (b *Bind) Action() string {
+	if b.Args == "" {
+		return b.Dispatcher
+	}
+	return b.Dispatcher + " " + b.Args
+}
+
+// Modified reports whether any field changed since the bind was parsed
+func (b *Bind) Modified() bool {
+	return b.fields() != b.original
+}
+
+// String renders the bind as a config line, keeping its flags
+// An unchanged parsed bind returns its original text exactly.
+func (b *Bind) String() string {
+	if b.Raw != "" && !b.Modified() {
+		return b.Raw
+	}
+	fields := []string{b.Mods, b.Key}
+	if b.HasDescription() {
+		fields = append(fields, b.Description)
+	}
+	fields = append(fields, b.Dispatcher)
+	if b.Args != "" {
+		fields = append(fields, b.Args)
+	}
+	return fmt.Sprintf("%s = %s", b.Keyword(), strings.Join(fields, ", "))
+}
+
+// ParseBind parses a bind directive (variables already resolved)
+func ParseBind(d Directive) (*Bind, error) {
+	flags, ok := ParseBindKeyword(d.Key)
+	if !ok {
+		return nil, fmt.Errorf("not a bind directive: %s", d.Key)
+	}
+	b, err := parseBindValue(flags, d.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", d.File, d.Line, err)
+	}
+	b.File = d.File
+	b.Line = d.Line
+	b.Raw = d.Source
+	return b, nil
+}
+
+// ParseBindLine parses a raw config line such as "bindd = SUPER, B, Browser, exec, firefox"
+// Variables are not resolved. Comment lines and non-bind lines return an error.
+func ParseBindLine(line string) (*Bind, error) {
+	stripped := strings.TrimSpace(StripComment(line))
+	keyword, value, ok := strings.Cut(stripped, "=")
+	if !ok {
+		return nil, fmt.Errorf("not a bind line: %s", stripped)
+	}
+	flags, ok := ParseBindKeyword(keyword)
+	if !ok {
+		return nil, fmt.Errorf("not a bind line: %s", stripped)
+	}
+	b, err := parseBindValue(flags, strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	b.Raw = line
+	return b, nil
+}
+
+// parseBindValue splits "MODS, KEY[, DESCRIPTION], DISPATCHER[, ARGS]"
+// Commas inside quotes never split a field. A description may contain commas:
+// it extends up to the first known dispatcher. The dispatcher arguments keep
+// their original text, commas included.
+func parseBindValue(flags BindFlags, value string) (*Bind, error) {
+	tokens := Tokenize(value)
+
+	minFields := 3
+	if flags.Has('d') {
+		minFields = 4
+	}
+	if len(tokens) < minFields {
+		return nil, fmt.Errorf("invalid bind%s format: %s", flags, value)
+	}
+
+	b := &Bind{Flags: flags, Mods: tokens[0].Text, Key: tokens[1].Text}
+	dispatcherIndex := 2
+	if flags.Has('d') {
+		dispatcherIndex = 3
+		for i := 3; i < len(tokens); i++ {
+			if IsDispatcher(tokens[i].Text) {
+				dispatcherIndex = i
+				break
+			}
+		}
+		b.Description = joinTokens(value, tokens[2:dispatcherIndex])
+	}
+	b.Dispatcher = tokens[dispatcherIndex].Text
+	b.Args = joinTokens(value, tokens[dispatcherIndex+1:])
+
+	b.original = b.fields()
+	return b, nil
+}
+
+// modifierAliases maps every modifier spelling Hyprland accepts (uppercase) to its canonical name
+var modifierAliases = map[string]string{
+	"SHIFT":   "SHIFT",
+	"CAPS":    "CAPS",
+	"CTRL":    "CTRL",
+	"CONTROL": "CTRL",
+	"ALT":     "ALT",
+	"MOD1":    "ALT",
+	"MOD2":    "MOD2",
+	"MOD3":    "MOD3",
+	"SUPER":   "SUPER",
+	"WIN":     "SUPER",
+	"MOD4":    "SUPER",
+	"LOGO":    "SUPER",
+	"MOD5":    "MOD5",
+}
+
+// keyAliases maps common key spellings (lowercase) to their XKB names
+var keyAliases = map[string]string{
+	"enter":     "Return",
+	"return":    "Return",
+	"esc":       "Escape",
+	"escape":    "Escape",
+	"space":     "Space",
+	"tab":       "Tab",
+	"del":       "Delete",
+	"delete":    "Delete",
+	"backspace": "BackSpace",
+	"print":     "Print",
+	"home":      "Home",
+	"end":       "End",
+	"left":      "Left",
+	"right":     "Right",
+	"up":        "Up",
+	"down":      "Down",
+}
+
+// CanonicalModifier returns the canonical name of a modifier spelling, e.g. "CTRL" for "control"
+func CanonicalModifier(name string) (string, bool) {
+	canonical, ok := modifierAliases[strings.ToUpper(strings.TrimSpace(name))]
+	return canonical, ok
+}
+
+// CanonicalKey uppercases single characters and applies keyAliases, e.g. "Return" for "enter"
+func CanonicalKey(key string) string {
+	key = strings.TrimSpace(key)
+	if utf8.RuneCountInString(key) == 1 {
+		return strings.ToUpper(key)
+	}
+	if alias, ok := keyAliases[strings.ToLower(key)]; ok {
+		return alias
+	}
+	return key
+}
+
+// IsKeyName reports whether a bare word (without modifiers) looks like a key
+func IsKeyName(word string) bool {
+	if utf8.RuneCountInString(word) == 1 {
+		return true
+	}
+	lower := strings.ToLower(word)
+	if _, ok := keyAliases[lower]; ok {
+		return true
+	}
+	if strings.HasPrefix(lower, "xf86") || strings.HasPrefix(lower, "code:") || strings.HasPrefix(lower, "mouse:") {
+		return true
+	}
+	if len(lower) >= 2 && lower[0] == 'f' {
+		for _, r := range lower[1:] {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Combo returns a normalized "MODS,key" string for comparing key combinations,
+// independent of modifier order, modifier and key spelling, and key case
+func Combo(mods, key string) string {
+	fields := strings.FieldsFunc(mods, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '_' || r == '+'
+	})
+	for i, field := range fields {
+		if canonical, ok := CanonicalModifier(field); ok {
+			fields[i] = canonical
+		} else {
+			fields[i] = strings.ToUpper(field)
+		}
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, " ") + "," + strings.ToLower(CanonicalKey(key))
+}
+
+// Combo returns the normalized key combination of the bind (see Combo)
+func (b *Bind) Combo() string {
+	return Combo(b.Mods, b.Key)
+}
+
+// ActiveBinds returns the binds that are in effect once the whole config is parsed:
+// binds inside a submap are skipped, and binds removed by a later "unbind" are dropped
+func (c *Config) ActiveBinds() []*Bind {
+	var binds []*Bind
+	submap := ""
+	for _, d := range c.Directives {
+		switch {
+		case d.Key == "submap":
+			submap = d.Value
+			if submap == "reset" {
+				submap = ""
+			}
+		case d.Key == "unbind":
+			if submap != "" {
+				continue
+			}
+			parts := strings.SplitN(d.Value, ",", 3)
+			if len(parts) < 2 {
+				continue
+			}
+			combo := Combo(parts[0], parts[1])
+			kept := binds[:0]
+			for _, b := range binds {
+				if b.Combo() != combo {
+					kept = append(kept, b)
+				}
+			}
+			binds = kept
+		case IsBindKeyword(d.Key):
+			if submap != "" {
+				continue
+			}
+			if b, err := ParseBind(d); err == nil {
+				binds = append(binds, b)
+			}
+		}
+	}
+	return binds
+}
@@ -0,0 +1,250 @@
+package hyprconf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBindKeepsSourceLine(t *testing.T) {
+	lines := []string{
+		"$terminal = uwsm app -- alacritty",
+		"bindd = SUPER, RETURN, Terminal, exec, $terminal",
+		"  bind=SUPER,Q,killactive   # close",
+		"bindel  =  , XF86AudioRaiseVolume, exec, wpctl set-volume @DEFAULT_AUDIO_SINK@ 5%+",
+	}
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	var binds []*Bind
+	for _, d := range config.Directives {
+		if IsBindKeyword(d.Key) {
+			b, err := ParseBind(d)
+			if err != nil {
+				t.Fatalf("ParseBind: %v", err)
+			}
+			binds = append(binds, b)
+		}
+	}
+	if len(binds) != 3 {
+		t.Fatalf("parsed %d binds, want 3", len(binds))
+	}
+	for i, b := range binds {
+		if got := b.String(); got != lines[i+1] {
+			t.Errorf("String() = %q, want the line as written %q", got, lines[i+1])
+		}
+	}
+
+	// Resolved values are still what the bind reports
+	if binds[0].Args != "uwsm app -- alacritty" {
+		t.Errorf("Args = %q, want the resolved variable", binds[0].Args)
+	}
+}
+
+func TestParseBindLine(t *testing.T) {
+	tests := []struct {
+		line        string
+		flags       BindFlags
+		mods, key   string
+		description string
+		dispatcher  string
+		args        string
+	}{
+		{"bindd = SUPER, RETURN, Terminal, exec, $terminal", "d", "SUPER", "RETURN", "Terminal", "exec", "$terminal"},
+		{"bindd = SUPER SHIFT, B, Browser (private), exec, $browser --private", "d", "SUPER SHIFT", "B", "Browser (private)", "exec", "$browser --private"},
+		{"bindeld = ,XF86AudioRaiseVolume, Volume up, exec, $osdclient --output-volume raise", "eld", "", "XF86AudioRaiseVolume", "Volume up", "exec", "$osdclient --output-volume raise"},
+		{"bindmd = SUPER, mouse:272, Move window, movewindow", "md", "SUPER", "mouse:272", "Move window", "movewindow", ""},
+		{"bindd = SUPER, W, Close active window, killactive,", "d", "SUPER", "W", "Close active window", "killactive", ""},
+		{"bindd = SUPER, code:10, Switch to workspace 1, workspace, 1", "d", "SUPER", "code:10", "Switch to workspace 1", "workspace", "1"},
+		{"bindld = , XF86PowerOff, Power menu, exec, omarchy-menu system", "ld", "", "XF86PowerOff", "Power menu", "exec", "omarchy-menu system"},
+		{"bind = SUPER, Q, killactive", "", "SUPER", "Q", "", "killactive", ""},
+		// Descriptions with commas run up to the first dispatcher
+		{"bindd = SUPER, PRINT, Screenshot, region, exec, omarchy-cmd-screenshot region", "d", "SUPER", "PRINT", "Screenshot, region", "exec", "omarchy-cmd-screenshot region"},
+		{"bindd = SUPER, K, Keys, bindings, and more, exec, omarchy-menu-keybindings", "d", "SUPER", "K", "Keys, bindings, and more", "exec", "omarchy-menu-keybindings"},
+		{`bindd = SUPER, N, "Notes, quick", exec, notes`, "d", "SUPER", "N", `"Notes, quick"`, "exec", "notes"},
+		// Arguments keep their commas and quotes
+		{`bind = SUPER, N, exec, notify-send "Hello, world"`, "", "SUPER", "N", "", "exec", `notify-send "Hello, world"`},
+		{"bind = SUPER, R, resizeactive, 10 -10", "", "SUPER", "R", "", "resizeactive", "10 -10"},
+		{"bind = SUPER, G, exec, hyprctl keyword general:gaps_out 5,5,5,5", "", "SUPER", "G", "", "exec", "hyprctl keyword general:gaps_out 5,5,5,5"},
+		// Apostrophes don't open a quote
+		{"bindd = SUPER ALT, SPACE, Omarchy's menu, exec, omarchy-menu", "d", "SUPER ALT", "SPACE", "Omarchy's menu", "exec", "omarchy-menu"},
+		{"bindd = SUPER, D, Don't disturb, exec, makoctl mode -t do-not-disturb", "d", "SUPER", "D", "Don't disturb", "exec", "makoctl mode -t do-not-disturb"},
+		// An unterminated quote is taken literally
+		{`bind = SUPER, X, exec, notify-send "oops, no end`, "", "SUPER", "X", "", "exec", `notify-send "oops, no end`},
+		// Comments are stripped, "##" is a literal #
+		{"bind = SUPER, C, exec, hyprpicker -a ## copy # pick a color", "", "SUPER", "C", "", "exec", "hyprpicker -a # copy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			b, err := ParseBindLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseBindLine: %v", err)
+			}
+			got := []string{string(b.Flags), b.Mods, b.Key, b.Description, b.Dispatcher, b.Args}
+			want := []string{string(tt.flags), tt.mods, tt.key, tt.description, tt.dispatcher, tt.args}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parsed %q, want %q", got, want)
+			}
+			if s := b.String(); s != tt.line {
+				t.Errorf("String() of unchanged bind = %q, want %q", s, tt.line)
+			}
+		})
+	}
+}
+
+func TestParseBindLineErrors(t *testing.T) {
+	for _, line := range []string{
+		"# bind = SUPER, Q, killactive",
+		"unbind = SUPER, Q",
+		"bindx = SUPER, Q, killactive",
+		"bindd = SUPER, Q, killactive", // a description bind needs four fields
+		"bind = SUPER, Q",
+		"monitor = , preferred, auto, 1",
+		"",
+	} {
+		if _, err := ParseBindLine(line); err == nil {
+			t.Errorf("ParseBindLine(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestParseBindKeywordEveryFlagCombination(t *testing.T) {
+	// Every subset of the flag letters, in their documented order
+	for mask := 0; mask < 1<<len(validBindFlags); mask++ {
+		var letters []byte
+		for i := 0; i < len(validBindFlags); i++ {
+			if mask&(1<<i) != 0 {
+				letters = append(letters, validBindFlags[i])
+			}
+		}
+		keyword := "bind" + string(letters)
+
+		flags, ok := ParseBindKeyword(keyword)
+		if !ok || string(flags) != string(letters) {
+			t.Fatalf("ParseBindKeyword(%q) = %q, %v", keyword, flags, ok)
+		}
+
+		value := "SUPER, B, exec, firefox"
+		if flags.Has('d') {
+			value = "SUPER, B, Browser, exec, firefox"
+		}
+		line := keyword + " = " + value
+		b, err := ParseBindLine(line)
+		if err != nil {
+			t.Fatalf("ParseBindLine(%q): %v", line, err)
+		}
+		if b.Keyword() != keyword || b.Dispatcher != "exec" || b.Args != "firefox" {
+			t.Fatalf("ParseBindLine(%q) = %+v", line, b)
+		}
+
+		// Rewriting keeps the flags
+		b.Key = "N"
+		want := strings.Replace(line, ", B,", ", N,", 1)
+		if got := b.String(); got != want {
+			t.Fatalf("String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestParseBindKeywordInvalid(t *testing.T) {
+	for _, keyword := range []string{"unbind", "bin", "bindx", "binddd", "bindee", "Bind", "bind d"} {
+		if _, ok := ParseBindKeyword(keyword); ok {
+			t.Errorf("ParseBindKeyword(%q) accepted an invalid keyword", keyword)
+		}
+	}
+	if flags, ok := ParseBindKeyword(" bindel "); !ok || flags != "el" {
+		t.Errorf(`ParseBindKeyword(" bindel ") = %q, %v`, flags, ok)
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	f := BindFlags("el")
+	if !f.Has('e') || f.Has('d') {
+		t.Errorf("Has is wrong for %q", f)
+	}
+	if got := f.With('d'); got != "eld" {
+		t.Errorf("With('d') = %q", got)
+	}
+	if got := f.With('e'); got != "el" {
+		t.Errorf("With('e') = %q", got)
+	}
+	if got := f.Without('e'); got != "l" {
+		t.Errorf("Without('e') = %q", got)
+	}
+	if !f.Equal("le") || f.Equal("ld") {
+		t.Errorf("Equal is wrong for %q", f)
+	}
+}
+
+func TestBindStringAfterChange(t *testing.T) {
+	tests := []struct {
+		line   string
+		change func(*Bind)
+		want   string
+	}{
+		{
+			"bindd   =  SUPER, B, Browser, exec, firefox   # my browser",
+			func(b *Bind) { b.Mods = "SUPER SHIFT" },
+			"bindd = SUPER SHIFT, B, Browser, exec, firefox",
+		},
+		{
+			"bindeld = , XF86AudioRaiseVolume, Volume up, exec, wpctl set-volume @DEFAULT_AUDIO_SINK@ 5%+",
+			func(b *Bind) { b.Flags = b.Flags.Without('d') },
+			"bindel = , XF86AudioRaiseVolume, exec, wpctl set-volume @DEFAULT_AUDIO_SINK@ 5%+",
+		},
+		{
+			"bind = SUPER, Q, killactive",
+			func(b *Bind) { b.Flags = b.Flags.With('d'); b.Description = "Close" },
+			"bindd = SUPER, Q, Close, killactive",
+		},
+		{
+			// Changing a field and back leaves the line untouched
+			"  bind=SUPER,Q,killactive",
+			func(b *Bind) { b.Key = "W"; b.Key = "Q" },
+			"  bind=SUPER,Q,killactive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			b, err := ParseBindLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseBindLine: %v", err)
+			}
+			tt.change(b)
+			if got := b.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombo(t *testing.T) {
+	tests := []struct {
+		mods, key string
+		want      string
+	}{
+		{"SUPER SHIFT", "B", "SHIFT SUPER,b"},
+		{"shift super", "b", "SHIFT SUPER,b"},
+		{"WIN_CONTROL", "RETURN", "CTRL SUPER,return"},
+		{"MOD4 + MOD1", "enter", "ALT SUPER,return"},
+		{"LOGO", "Esc", "SUPER,escape"},
+		{"", "XF86AudioMute", ",xf86audiomute"},
+		{"SUPER CAPS", "space", "CAPS SUPER,space"},
+		{"SUPER", " code:10 ", "SUPER,code:10"},
+	}
+	for _, tt := range tests {
+		if got := Combo(tt.mods, tt.key); got != tt.want {
+			t.Errorf("Combo(%q, %q) = %q, want %q", tt.mods, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package hyprconf parses Hyprland configuration files.
+// It only depends on the standard library so that both the config and hypr
+// packages can use it without an import cycle.
+package hyprconf
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultConfigPath is the main Hyprland configuration file
+const DefaultConfigPath = "~/.config/hypr/hyprland.conf"
+
+// maxSourceDepth guards against runaway source= recursion
+const maxSourceDepth = 32
+
+// Directive is a single "key = value" line of a Hyprland config
+type Directive struct {
+	Key     string // e.g. "bindd", "windowrulev2", or "input:kb_layout" inside a section
+	Value   string // value with variables resolved and comments stripped
+	Raw     string // value as written, before variable substitution
+	Source  string // the whole line as written, indentation and comment included
+	File    string // file the directive was read from
+	Line    int    // 1-based line number in File
+	Section string // enclosing category, e.g. "input:touchpad" ("" at top level)
+}
+
+// Config is the result of parsing a Hyprland config and everything it sources
+type Config struct {
+	Directives []Directive
+	Variables  map[string]string // name (without $) -> resolved value
+	Files      []string          // every parsed file, in parse order
+	Errors     []error           // non-fatal problems (missing sources, bad lines)
+}
+
+// ParseFile parses path and follows its source= includes
+func ParseFile(path string) (*Config, error) {
+	p := &parser{
+		config: &Config{Variables: make(map[string]string)},
+		seen:   make(map[string]bool),
+	}
+	if err := p.parseFile(path, 0); err != nil {
+		return nil, err
+	}
+	return p.config, nil
+}
+
+// Find returns the directives whose key satisfies match
+func (c *Config) Find(match func(key string) bool) []Directive {
+	var found []Directive
+	for _, d := range c.Directives {
+		if match(d.Key) {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// ExpandPath expands a leading ~ to the user's home directory
+func ExpandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, path[1:]), nil
+}
+
+// parser holds the state shared across sourced files
+type parser struct {
+	config *Config
+	seen   map[string]bool
+}
+
+// parseFile parses a single file, recursing into source= directives
+func (p *parser) parseFile(path string, depth int) error {
+	if depth > maxSourceDepth {
+		return fmt.Errorf("%s: source nesting too deep", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	if p.seen[path] {
+		// Already parsed, avoid include cycles
+		return nil
+	}
+	p.seen[path] = true
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p.config.Files = append(p.config.Files, path)
+
+	var sections []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		source := scanner.Text()
+		line := strings.TrimSpace(StripComment(source))
+		if line == "" {
+			continue
+		}
+
+		// Section end
+		if line == "}" {
+			if len(sections) > 0 {
+				sections = sections[:len(sections)-1]
+			}
+			continue
+		}
+
+		// Section start, e.g. "input {" or "device {"
+		if strings.HasSuffix(line, "{") && !strings.Contains(line, "=") {
+			name := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			sections = append(sections, name)
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			p.config.Errors = append(p.config.Errors, fmt.Errorf("%s:%d: invalid line: %s", path, lineNum, line))
+			continue
+		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+
+		// Variable definition
+		if strings.HasPrefix(key, "$") && len(sections) == 0 {
+			p.config.Variables[key[1:]] = p.substitute(raw)
+			continue
+		}
+
+		section := strings.Join(sections, ":")
+		fullKey := key
+		if section != "" {
+			fullKey = section + ":" + key
+		}
+
+		d := Directive{
+			Key:     fullKey,
+			Value:   p.substitute(raw),
+			Raw:     raw,
+			Source:  source,
+			File:    path,
+			Line:    lineNum,
+			Section: section,
+		}
+		p.config.Directives = append(p.config.Directives, d)
+
+		if fullKey == "source" {
+			p.source(d, filepath.Dir(path), depth)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// source parses the files matched by a source= directive
+func (p *parser) source(d Directive, dir string, depth int) {
+	pattern, err := ExpandPath(d.Value)
+	if err != nil {
+		p.config.Errors = append(p.config.Errors, fmt.Errorf("%s:%d: %w", d.File, d.Line, err))
+		return
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		p.config.Errors = append(p.config.Errors, fmt.Errorf("%s:%d: bad source pattern %q: %w", d.File, d.Line, d.Value, err))
+		return
+	}
+	if len(matches) == 0 {
+		p.config.Errors = append(p.config.Errors, fmt.Errorf("%s:%d: source file not found: %s", d.File, d.Line, d.Value))
+		return
+	}
+
+	for _, match := range matches {
+		if err := p.parseFile(match, depth+1); err != nil {
+			p.config.Errors = append(p.config.Errors, fmt.Errorf("%s:%d: %w", d.File, d.Line, err))
+		}
+	}
+}
+
+// substitute replaces $variables in value with the values defined so far
+func (p *parser) substitute(value string) string {
+	return p.config.Substitute(value)
+}
+
+// Substitute replaces $variables in value, longest names first so that
+// $mainModShift isn't mistaken for $mainMod followed by "Shift"
+func (c *Config) Substitute(value string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	names := make([]string, 0, len(c.Variables))
+	for name := range c.Variables {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	for _, name := range names {
+		value = strings.ReplaceAll(value, "$"+name, c.Variables[name])
+	}
+	return value
+}
+
+// StripComment removes a trailing # comment; "##" is an escaped literal #
+func StripComment(line string) string {
+	if !strings.Contains(line, "#") {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] != '#' {
+			b.WriteByte(line[i])
+			continue
+		}
+		if i+1 < len(line) && line[i+1] == '#' {
+			b.WriteByte('#')
+			i++
+			continue
+		}
+		break
+	}
+	return b.String()
+}
+
+// EscapeComment escapes every # in value as "##" so StripComment gives value back
+func EscapeComment(value string) string {
+	return strings.ReplaceAll(value, "#", "##")
+}
@@ -0,0 +1,98 @@
+package hyprconf
+
+import "strings"
+
+// Token is one comma-separated field of a directive value
+type Token struct {
+	Text  string // field text with surrounding whitespace trimmed (quotes kept)
+	Start int    // byte offset of the untrimmed field in the value
+	End   int    // byte offset just past the untrimmed field
+}
+
+// Tokenize splits value on commas that are outside single or double quotes
+// Offsets refer to value, so value[tokens[i].Start:tokens[j].End] recovers
+// the original text of a run of fields including the commas between them.
+// A quote only opens at the start of a word, so apostrophes ("Omarchy's menu")
+// don't swallow the rest of the line; an unterminated quote is ignored.
+func Tokenize(value string) []Token {
+	if tokens, ok := tokenize(value, true); ok {
+		return tokens
+	}
+	tokens, _ := tokenize(value, false)
+	return tokens
+}
+
+// tokenize splits value on commas, honoring quotes if quoted is set
+// ok is false if a quote is left open.
+func tokenize(value string, quoted bool) (tokens []Token, ok bool) {
+	start := 0
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(value) {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case quoted && (c == '"' || c == '\'') && startsWord(value, i):
+			quote = c
+		case c == ',':
+			tokens = append(tokens, newToken(value, start, i))
+			start = i + 1
+		}
+	}
+	return append(tokens, newToken(value, start, len(value))), quote == 0
+}
+
+// startsWord reports whether value[i] begins a word
+func startsWord(value string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := value[i-1]
+	return prev == ' ' || prev == '\t' || prev == ',' || prev == '='
+}
+
+// newToken builds the token for value[start:end]
+func newToken(value string, start, end int) Token {
+	return Token{Text: strings.TrimSpace(value[start:end]), Start: start, End: end}
+}
+
+// joinTokens returns the original text spanning tokens (inclusive), trimmed
+func joinTokens(value string, tokens []Token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(value[tokens[0].Start:tokens[len(tokens)-1].End])
+}
+
+// dispatchers are the Hyprland dispatcher names, used to find where a
+// description that contains commas ends
+var dispatchers = map[string]bool{
+	"exec": true, "execr": true, "pass": true, "sendshortcut": true, "sendkeystate": true,
+	"killactive": true, "forcekillactive": true, "closewindow": true, "killwindow": true,
+	"signal": true, "signalwindow": true, "workspace": true, "movetoworkspace": true,
+	"movetoworkspacesilent": true, "togglefloating": true, "setfloating": true, "settiled": true,
+	"fullscreen": true, "fullscreenstate": true, "dpms": true, "pin": true, "movefocus": true,
+	"movewindow": true, "swapwindow": true, "centerwindow": true, "resizeactive": true,
+	"moveactive": true, "resizewindowpixel": true, "movewindowpixel": true, "cyclenext": true,
+	"swapnext": true, "tagwindow": true, "focuswindow": true, "focusmonitor": true,
+	"splitratio": true, "movecursortocorner": true, "movecursor": true, "renameworkspace": true,
+	"exit": true, "forcerendererreload": true, "movecurrentworkspacetomonitor": true,
+	"focusworkspaceoncurrentmonitor": true, "moveworkspacetomonitor": true,
+	"swapactiveworkspaces": true, "bringactivetotop": true, "alterzorder": true,
+	"togglespecialworkspace": true, "focusurgentorlast": true, "togglegroup": true,
+	"changegroupactive": true, "focuscurrentorlast": true, "lockgroups": true,
+	"lockactivegroup": true, "moveintogroup": true, "moveoutofgroup": true,
+	"movewindoworgroup": true, "movegroupwindow": true, "denywindowfromgroup": true,
+	"setignoregrouplock": true, "global": true, "submap": true, "event": true, "setprop": true,
+	"toggleswallow": true, "layoutmsg": true, "togglesplit": true, "swapsplit": true,
+	"pseudo": true, "resizewindow": true,
+}
+
+// IsDispatcher reports whether name is a known Hyprland dispatcher
+func IsDispatcher(name string) bool {
+	return dispatchers[strings.ToLower(name)]
+}
@@ -0,0 +1,103 @@
+package hyprconf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"plain", "SUPER, B, exec, firefox", []string{"SUPER", "B", "exec", "firefox"}},
+		{"empty mods", ", XF86AudioMute, exec, pamixer -t", []string{"", "XF86AudioMute", "exec", "pamixer -t"}},
+		{"empty trailing field", "SUPER, Q, killactive,", []string{"SUPER", "Q", "killactive", ""}},
+		{
+			"double quoted comma",
+			`SUPER, N, exec, notify-send "Hello, world"`,
+			[]string{"SUPER", "N", "exec", `notify-send "Hello, world"`},
+		},
+		{
+			"single quoted comma",
+			`SUPER, P, exec, sh -c 'grim -g "$(slurp)" - | wl-copy, then notify'`,
+			[]string{"SUPER", "P", "exec", `sh -c 'grim -g "$(slurp)" - | wl-copy, then notify'`},
+		},
+		{
+			"quoted description",
+			`SUPER, K, "Show keybindings, all of them", exec, omarchy-menu-keybindings`,
+			[]string{"SUPER", "K", `"Show keybindings, all of them"`, "exec", "omarchy-menu-keybindings"},
+		},
+		{
+			"escaped double quote",
+			`SUPER, E, exec, sh -c "echo \"a, b\""`,
+			[]string{"SUPER", "E", "exec", `sh -c "echo \"a, b\""`},
+		},
+		{
+			"apostrophe inside a word",
+			"SUPER ALT, SPACE, Omarchy's menu, exec, omarchy-menu",
+			[]string{"SUPER ALT", "SPACE", "Omarchy's menu", "exec", "omarchy-menu"},
+		},
+		{
+			"apostrophes in description and args",
+			"SUPER, M, Today's music, exec, notify-send it's, loud",
+			[]string{"SUPER", "M", "Today's music", "exec", "notify-send it's", "loud"},
+		},
+		{
+			"unterminated double quote",
+			`SUPER, X, exec, notify-send "oops, no end`,
+			[]string{"SUPER", "X", "exec", `notify-send "oops`, "no end"},
+		},
+		{
+			"unterminated single quote",
+			`SUPER, Y, 'Broken, description, exec, app`,
+			[]string{"SUPER", "Y", "'Broken", "description", "exec", "app"},
+		},
+		{"tabs and spaces", "SUPER\t,\tB ,  exec ,firefox  ", []string{"SUPER", "B", "exec", "firefox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := Tokenize(tt.value)
+			var got []string
+			for _, token := range tokens {
+				got = append(got, token.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+
+			// Offsets cover the value exactly, separated by the commas
+			for i, token := range tokens {
+				if i > 0 && token.Start != tokens[i-1].End+1 {
+					t.Errorf("token %d starts at %d, previous ends at %d", i, token.Start, tokens[i-1].End)
+				}
+			}
+			if tokens[0].Start != 0 || tokens[len(tokens)-1].End != len(tt.value) {
+				t.Errorf("tokens span [%d, %d), want [0, %d)", tokens[0].Start, tokens[len(tokens)-1].End, len(tt.value))
+			}
+		})
+	}
+}
+
+func TestJoinTokens(t *testing.T) {
+	value := "SUPER, B, Browser, with tabs , exec, firefox --new-window, --private"
+	tokens := Tokenize(value)
+	tests := []struct {
+		from, to int
+		want     string
+	}{
+		{2, 3, "Browser, with tabs"},
+		{5, 6, "firefox --new-window, --private"},
+		{0, 0, "SUPER"},
+	}
+	for _, tt := range tests {
+		if got := joinTokens(value, tokens[tt.from:tt.to+1]); got != tt.want {
+			t.Errorf("joinTokens(%d..%d) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+	if got := joinTokens(value, nil); got != "" {
+		t.Errorf("joinTokens(nil) = %q, want empty", got)
+	}
+}
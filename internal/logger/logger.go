@@ -61,7 +61,7 @@ func (l *Logger) Log(format string, args ...interface{}) {
 	if err != nil {
 		// Fallback to stderr if write fails
 		fmt.Fprintf(os.Stderr, "[LOGGER ERROR] Failed to write to log file: %v\n", err)
-		fmt.Fprintf(os.Stderr, logLine)
+		fmt.Fprint(os.Stderr, logLine)
 	}
 }
 
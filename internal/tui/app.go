@@ -23,6 +23,7 @@ type App struct {
 	categoriesView *CategoriesView
 	appsView       *AppsView
 	bottomPanel    *BottomPanel
+	driftView      *DriftView
 	root           *tview.Flex
 	focusedPanel   FocusedPanel
 }
@@ -41,17 +42,21 @@ func NewApp(cfg *config.OmarchyConfig) (*App, error) {
 	tempRoot := tview.NewBox()
 
 	// Create views
-	a.categoriesView = NewCategoriesView(a.controller, func(categoryID string) {
+	a.categoriesView = NewCategoriesView(a.controller, a.app, tempRoot, func(categoryID string) {
 		a.onCategoryChange(categoryID)
 	})
 	a.appsView = NewAppsView(a.controller, a.app, tempRoot)
 	a.bottomPanel = NewBottomPanel(a.controller)
+	a.driftView = NewDriftView(a.app, tempRoot, a.restoreFocus)
 
 	// Set up layout
 	a.setupLayout()
 
-	// Update apps view with real root
+	// Update views with real root
 	a.appsView.root = a.root
+	a.categoriesView.root = a.root
+	a.driftView.root = a.root
+	a.categoriesView.onDefaultChange = a.appsView.Refresh
 
 	// Register state change callback after all views are created
 	a.controller.SetStateChangeCallback(func() {
@@ -99,6 +104,18 @@ func (a *App) setupLayout() {
 // All events are handled at the application level
 func (a *App) setupGlobalKeyHandlers() {
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// The keybinding dialog records chords and modifier toggles itself
+		if a.appsView.HandleKeybindingKey(event) {
+			return nil
+		}
+
+		// Dialogs (inputs, modals, pickers) get every other key untouched, so
+		// typing 'q' or moving the cursor doesn't trigger panel shortcuts
+		focus := a.app.GetFocus()
+		if focus != a.categoriesView.GetList() && focus != a.appsView.GetList() {
+			return event
+		}
+
 		// Global shortcuts - consume immediately
 		if event.Key() == tcell.KeyRune && event.Rune() == 'q' {
 			logger.Log("Quit key pressed, stopping application")
@@ -106,6 +123,20 @@ func (a *App) setupGlobalKeyHandlers() {
 			return nil
 		}
 
+		// Quick-launch the selected app from the apps list
+		if event.Key() == tcell.KeyRune && event.Rune() == 'l' && a.app.GetFocus() == a.appsView.GetList() {
+			logger.Log("Quick-launch key pressed")
+			a.appsView.LaunchSelected()
+			return nil
+		}
+
+		// Compare the live Hyprland binds with the config files
+		if event.Key() == tcell.KeyRune && event.Rune() == 'd' {
+			logger.Log("Drift key pressed")
+			a.driftView.Show()
+			return nil
+		}
+
 		// Handle Esc for edit mode cancellation
 		if event.Key() == tcell.KeyEscape {
 			if a.controller.GetEditMode() != EditModeNone {
@@ -154,6 +185,15 @@ func (a *App) setupGlobalKeyHandlers() {
 	})
 }
 
+// restoreFocus gives focus back to the focused panel's list after a dialog closes
+func (a *App) restoreFocus() {
+	if a.focusedPanel == FocusPanelApps {
+		a.app.SetFocus(a.appsView.GetList())
+	} else {
+		a.app.SetFocus(a.categoriesView.GetList())
+	}
+}
+
 // onCategoryChange handles category selection changes
 func (a *App) onCategoryChange(categoryID string) {
 	logger.Log("Category changed to: %s", categoryID)
@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/exec"
 	"omarchy-tui/internal/hypr"
 	"omarchy-tui/internal/logger"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -17,6 +20,7 @@ type AppsView struct {
 	apps       []config.Application
 	app        *tview.Application
 	root       tview.Primitive
+	keyCapture *keyCapture // set while the keybinding dialog is open
 }
 
 // NewAppsView creates a new apps view
@@ -64,17 +68,16 @@ func (av *AppsView) LoadApps(apps []config.Application) {
 	av.apps = apps
 	av.list.Clear()
 
-	for _, app := range av.apps {
+	for i, app := range av.apps {
 		// Check if this app is default for its category
-		defaultApp := av.controller.GetDefaultAppForCategory(app.Category)
 		mainText := app.Name
-		if defaultApp != nil && app.PackageName == defaultApp.PackageName {
+		if av.controller.IsDefaultApp(&av.apps[i]) {
 			mainText = "* " + mainText
 		}
-		// Format secondary text with keybinding
+		// Format secondary text with keybindings
 		secondaryText := "└─ NONE"
-		if app.Keybinding != "" {
-			secondaryText = fmt.Sprintf("└─ %s", app.Keybinding)
+		if len(app.Keybindings) > 0 {
+			secondaryText = "└─ " + keybindingsSummary(&av.apps[i])
 		}
 		av.list.AddItem(mainText, secondaryText, 0, nil)
 	}
@@ -91,6 +94,16 @@ func (av *AppsView) LoadApps(apps []config.Application) {
 	}
 }
 
+// Refresh re-renders the current apps (e.g. after a default change), keeping the selection
+func (av *AppsView) Refresh() {
+	currentIndex := av.list.GetCurrentItem()
+	av.LoadApps(av.apps)
+	if currentIndex >= 0 && currentIndex < len(av.apps) {
+		av.list.SetCurrentItem(currentIndex)
+		av.controller.SetSelectedAppSilent(&av.apps[currentIndex])
+	}
+}
+
 // GetSelected returns the currently selected application
 func (av *AppsView) GetSelected() *config.Application {
 	index := av.list.GetCurrentItem()
@@ -108,96 +121,356 @@ func (av *AppsView) UpdateSelection() {
 	}
 }
 
-// showActionMenu displays a modal with action options
+// appAction is an entry of the action menu
+type appAction struct {
+	name string
+	run  func()
+}
+
+// showActionMenu lists the actions available for app
 func (av *AppsView) showActionMenu(app *config.Application) {
 	logger.Log("showActionMenu: Called for app: %s", app.Name)
 
+	actions := []appAction{
+		{"Launch", func() { av.LaunchApp(app) }},
+		{"Set as default", func() {
+			err := av.controller.SetDefaultApp(app.Category, app)
+			// Refresh even on failure: part of the change may already be saved
+			av.Refresh()
+			if err != nil {
+				av.showErrorModal(fmt.Sprintf("Failed to set default app: %v", err))
+			}
+		}},
+		{"Set keybinding", func() {
+			choices := append(keybindingChoices(app), keybindingChoice{text: "+ Add keybinding"})
+			av.showKeybindingPicker(app, " Set keybinding ", choices, func(choice keybindingChoice) {
+				if choice.label == "" {
+					av.showLabelInput(app)
+					return
+				}
+				av.showKeybindingInput(app, choice.label)
+			})
+		}},
+	}
+	if len(app.Keybindings) > 0 {
+		actions = append(actions, appAction{"Remove keybinding", func() {
+			av.showKeybindingPicker(app, " Remove keybinding ", keybindingChoices(app), func(choice keybindingChoice) {
+				av.resetKeybinding(app, choice.label, hypr.RemoveKeybinding)
+			})
+		}})
+	}
+	if overridden := overriddenKeybindings(app); len(overridden) > 0 {
+		actions = append(actions, appAction{"Restore original binding", func() {
+			av.showKeybindingPicker(app, " Restore original binding ", overridden, func(choice keybindingChoice) {
+				av.resetKeybinding(app, choice.label, hypr.RestoreKeybinding)
+			})
+		}})
+	}
+	actions = append(actions,
+		appAction{"Window rules", func() { av.showWindowRules(app) }},
+		appAction{"Edit configuration", func() { av.controller.EnterEditMode(EditModeAppConfig) }},
+	)
+
+	closeMenu := func() {
+		av.app.SetRoot(av.root, true)
+		av.app.SetFocus(av.list)
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	for _, action := range actions {
+		list.AddItem(action.name, "", 0, nil)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		logger.Log("showActionMenu: Picked '%s' for app %s", actions[index].name, app.Name)
+		closeMenu()
+		actions[index].run()
+	})
+	list.SetDoneFunc(closeMenu)
+
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Actions for %s ", app.Name)).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(list, 60, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			len(actions)+2, 0, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	av.app.SetRoot(dialog, true)
+	av.app.SetFocus(list)
+}
+
+// LaunchSelected launches the currently selected application (quick-launch key)
+func (av *AppsView) LaunchSelected() {
+	if app := av.GetSelected(); app != nil {
+		av.LaunchApp(app)
+	}
+}
+
+// LaunchApp launches app through the controller and shows a modal if it fails,
+// either right away or when it exits shortly after starting
+func (av *AppsView) LaunchApp(app *config.Application) {
+	err := av.controller.LaunchApp(app, func(err error) {
+		av.app.QueueUpdateDraw(func() {
+			logger.Log("LaunchApp: %s exited right after starting: %v", app.Name, err)
+			av.controller.RecordLaunchError(err)
+			av.showErrorModal(launchErrorText(err))
+		})
+	})
+	if err != nil {
+		logger.Log("LaunchApp: Failed to launch %s: %v", app.Name, err)
+		av.showErrorModal(launchErrorText(err))
+	}
+}
+
+// launchErrorText formats a launch failure for display, including the output log tail
+func launchErrorText(err error) string {
+	text := err.Error()
+
+	var launchErr *exec.LaunchError
+	if errors.As(err, &launchErr) {
+		if len(launchErr.LogTail) > 0 {
+			text += "\n\n" + strings.Join(launchErr.LogTail, "\n")
+		}
+		if launchErr.LogPath != "" {
+			text += "\n\nLog: " + launchErr.LogPath
+		}
+	}
+	return text
+}
+
+// showErrorModal displays an error message until dismissed
+func (av *AppsView) showErrorModal(message string) {
 	modal := tview.NewModal().
-		SetText("Select action for " + app.Name).
-		AddButtons([]string{"Set keybinding", "Edit configuration", "Cancel"}).
+		SetText(tview.Escape(message)).
+		AddButtons([]string{"OK"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			logger.Log("showActionMenu: Modal button pressed: %s (index: %d)", buttonLabel, buttonIndex)
 			av.app.SetRoot(av.root, true)
 			av.app.SetFocus(av.list)
-
-			switch buttonLabel {
-			case "Set keybinding":
-				av.showKeybindingInput(app)
-				// Note: Don't restore root here, let showKeybindingInput handle it
-			case "Edit configuration":
-				av.controller.EnterEditMode(EditModeAppConfig)
-			}
 		})
+	modal.SetTitle(" Error ").SetBorder(true)
 
-	logger.Log("showActionMenu: Modal created, setting root to modal")
 	av.app.SetRoot(modal, true)
-	logger.Log("showActionMenu: Root set to modal, setting focus to modal")
 	av.app.SetFocus(modal)
-	logger.Log("showActionMenu: Focus set to modal, modal should now be visible")
 }
 
-// showKeybindingInput displays an input dialog for setting a keybinding
-func (av *AppsView) showKeybindingInput(app *config.Application) {
-	logger.Log("showKeybindingInput: Called for app: %s", app.Name)
+// keybindingsSummary formats all of app's keybindings on one line, naming those
+// whose label differs from the app name
+func keybindingsSummary(app *config.Application) string {
+	var parts []string
+	for _, binding := range app.Keybindings {
+		part := binding.Keys.String()
+		if label := app.KeybindingLabel(binding); !strings.EqualFold(label, app.Name) {
+			part += fmt.Sprintf(" (%s)", label)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// keybindingChoice is an entry of the keybinding picker
+// An empty label stands for a new keybinding.
+type keybindingChoice struct {
+	label string
+	text  string
+}
+
+// keybindingChoices returns a picker entry for each of app's keybindings
+func keybindingChoices(app *config.Application) []keybindingChoice {
+	var choices []keybindingChoice
+	for _, binding := range app.Keybindings {
+		label := app.KeybindingLabel(binding)
+		choices = append(choices, keybindingChoice{
+			label: label,
+			text:  fmt.Sprintf("%s: %s", label, binding.Keys),
+		})
+	}
+	return choices
+}
+
+// overriddenKeybindings returns a picker entry for each of app's keybindings with
+// changes in bindings.conf that can be restored
+func overriddenKeybindings(app *config.Application) []keybindingChoice {
+	choices := keybindingChoices(app)
+	labels := []string{app.Name}
+	for _, choice := range choices {
+		labels = append(labels, choice.label)
+	}
+	overrides := hypr.KeybindingOverrides(labels)
+
+	var overridden []keybindingChoice
+	for _, choice := range choices {
+		if overrides[choice.label] {
+			overridden = append(overridden, choice)
+		}
+	}
+	// A removed main binding is no longer listed but can still be restored
+	if app.FindKeybinding("") < 0 && overrides[app.Name] {
+		overridden = append(overridden, keybindingChoice{label: app.Name, text: app.Name})
+	}
+	return overridden
+}
+
+// showKeybindingPicker lets the user choose which of app's keybindings to act on
+// A single choice is picked right away.
+func (av *AppsView) showKeybindingPicker(app *config.Application, title string, choices []keybindingChoice, onPick func(choice keybindingChoice)) {
+	if len(choices) == 1 {
+		onPick(choices[0])
+		return
+	}
+
+	closePicker := func() {
+		av.app.SetRoot(av.root, true)
+		av.app.SetFocus(av.list)
+	}
+
+	picker := tview.NewList().ShowSecondaryText(false)
+	for _, choice := range choices {
+		picker.AddItem(choice.text, "", 0, nil)
+	}
+
+	picker.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		closePicker()
+		logger.Log("showKeybindingPicker: Picked '%s' for app %s", choices[index].label, app.Name)
+		onPick(choices[index])
+	})
+	picker.SetDoneFunc(closePicker)
+
+	picker.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(picker, 60, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			0, 1, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	av.app.SetRoot(dialog, true)
+	av.app.SetFocus(picker)
+}
+
+// showLabelInput asks for the label of a new keybinding, then opens the keybinding dialog
+// The label becomes the bind description, so it must not be used by another binding.
+func (av *AppsView) showLabelInput(app *config.Application) {
+	inputField := tview.NewInputField().
+		SetLabel("Label: ").
+		SetText(app.NewKeybindingLabel()).
+		SetFieldWidth(40)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			label := strings.TrimSpace(inputField.GetText())
+			if label == "" || app.FindKeybinding(label) >= 0 {
+				av.showErrorModal(fmt.Sprintf("Label '%s' is empty or already used", label))
+				return
+			}
+			av.showKeybindingInput(app, label)
+		case tcell.KeyEscape:
+			av.app.SetRoot(av.root, true)
+			av.app.SetFocus(av.list)
+		}
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(" New Keybinding ").
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(inputField, 60, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			3, 0, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	av.app.SetRoot(dialog, true)
+	av.app.SetFocus(inputField)
+}
+
+// showKeybindingInput displays an input dialog for setting the keybinding labelled label
+func (av *AppsView) showKeybindingInput(app *config.Application, label string) {
+	logger.Log("showKeybindingInput: Called for app: %s, keybinding: %s", app.Name, label)
+
+	// Pre-fill with the current keybinding
+	current := ""
+	if i := app.FindKeybinding(label); i >= 0 {
+		current = app.Keybindings[i].Keys.String()
+	}
 
 	// Create input field
 	inputField := tview.NewInputField().
-		SetLabel(fmt.Sprintf("Keybinding for %s: ", app.Name)).
-		SetText(app.Keybinding). // Pre-fill with current keybinding
+		SetLabel(fmt.Sprintf("Keybinding for %s: ", label)).
+		SetText(current).
 		SetFieldWidth(40)
 
+	// Key capture mode and modifier toggles, driven by the central router
+	av.keyCapture = newKeyCapture(inputField)
+
+	var finalDialog tview.Primitive
+
 	// Set up done callback (must be after inputField is created)
 	inputField.SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEnter {
-			keybinding := inputField.GetText()
-			if err := hypr.AddKeybinding(app.Name, app.PackageName, keybinding); err != nil {
-				logger.Log("Failed to add keybinding: %v", err)
-			} else {
-				logger.Log("Keybinding saved: %s -> %s", app.Name, keybinding)
-				// Store current selection index before reload
-				currentIndex := av.list.GetCurrentItem()
-
-				// Reload config from disk
-				if err := av.controller.ReloadConfig(); err != nil {
-					logger.Log("Failed to reload config: %v", err)
-				}
-
-				// Refresh apps list to show updated keybinding (filtered by current category)
-				av.LoadApps(av.controller.GetFilteredApps())
+			text := inputField.GetText()
+			keybinding, err := config.ParseKeybinding(text)
+			if err == nil && !keybinding.IsValid() {
+				err = fmt.Errorf("keybinding is empty")
+			}
+			if err != nil {
+				av.showErrorModal(fmt.Sprintf("Invalid keybinding: %v", err))
+				return
+			}
 
-				// Restore selection if still valid
-				if currentIndex >= 0 && currentIndex < len(av.apps) {
-					av.list.SetCurrentItem(currentIndex)
-					// Update controller selection
-					if currentIndex < len(av.apps) {
-						av.controller.SetSelectedAppSilent(&av.apps[currentIndex])
-					}
-				}
+			conflicts, err := hypr.FindConflicts(keybinding, app, label)
+			if err != nil {
+				// Not fatal, the binding can still be saved
+				logger.Log("showKeybindingInput: Failed to check conflicts: %v", err)
 			}
-			// Return to main view
-			av.app.SetRoot(av.root, true)
-			av.app.SetFocus(av.list)
+			warnings := hypr.UnbindWarnings(app, label, conflicts)
+			if len(conflicts) > 0 || len(warnings) > 0 {
+				av.showKeybindingConflicts(app, label, keybinding, conflicts, warnings, func() {
+					av.app.SetRoot(finalDialog, true)
+					av.app.SetFocus(inputField)
+				})
+				return
+			}
+
+			av.saveKeybinding(app, label, text, nil)
 		} else if key == tcell.KeyEscape {
 			// Cancel, return to main view
 			logger.Log("Keybinding input cancelled")
+			av.keyCapture = nil
 			av.app.SetRoot(av.root, true)
 			av.app.SetFocus(av.list)
 		}
 	})
 
-	// Create instructions text
-	instructions := tview.NewTextView().
-		SetText("Press Enter to save, Esc to cancel").
-		SetTextAlign(tview.AlignCenter).
-		SetDynamicColors(false)
-
 	// Create Flex container with border and title
 	dialog := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(tview.NewBox().SetBorder(false), 1, 0, false). // Spacer
-		AddItem(instructions, 1, 0, false).
+		AddItem(av.keyCapture.instructions, 1, 0, false).
 		AddItem(tview.NewBox().SetBorder(false), 1, 0, false). // Spacer
 		AddItem(inputField, 1, 0, true).                       // Input field (focusable)
+		AddItem(tview.NewBox().SetBorder(false), 1, 0, false). // Spacer
+		AddItem(av.keyCapture.toggles, 1, 0, false).           // Modifier toggles
 		AddItem(tview.NewBox().SetBorder(false), 1, 0, false)  // Spacer
 
 	dialog.SetBorder(true).
@@ -205,7 +478,7 @@ func (av *AppsView) showKeybindingInput(app *config.Application) {
 		SetTitleAlign(tview.AlignCenter)
 
 	// Create centered container
-	finalDialog := tview.NewFlex().
+	finalDialog = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(tview.NewBox(), 0, 1, false).
 		AddItem(tview.NewFlex().
@@ -220,3 +493,123 @@ func (av *AppsView) showKeybindingInput(app *config.Application) {
 	logger.Log("showKeybindingInput: Setting focus to input field")
 	av.app.SetFocus(inputField)
 }
+
+// HandleKeybindingKey gives the open keybinding dialog a chance to consume a key event
+// (capture mode and modifier toggles); returns false if the event should be forwarded
+func (av *AppsView) HandleKeybindingKey(event *tcell.EventKey) bool {
+	if av.keyCapture == nil || av.app.GetFocus() != av.keyCapture.input {
+		return false
+	}
+	return av.keyCapture.handle(event)
+}
+
+// showKeybindingConflicts warns that keybinding is already bound, or that binds it
+// replaces can't be unbound (warnings), and lets the user save it anyway as app's
+// keybinding labelled label or go back to the keybinding dialog
+func (av *AppsView) showKeybindingConflicts(app *config.Application, label string, keybinding config.Keybinding, conflicts []hypr.Conflict, warnings []string, back func()) {
+	var text strings.Builder
+	if len(conflicts) > 0 {
+		fmt.Fprintf(&text, "%s is already bound:\n", keybinding)
+		for _, conflict := range conflicts {
+			fmt.Fprintf(&text, "\n%s\n%s\n", conflict.Action(), conflict.Location())
+		}
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(&text, "\nWarning: %s\n", warning)
+	}
+	action, title := "Reassign", " Keybinding Conflict "
+	if len(conflicts) > 0 {
+		fmt.Fprintf(&text, "\nReassign it to %s?", app.Name)
+	} else {
+		action, title = "Save", " Keybinding Warning "
+		fmt.Fprintf(&text, "\nSave %s for %s anyway?", keybinding, app.Name)
+	}
+
+	modal := tview.NewModal().
+		SetText(tview.Escape(strings.TrimPrefix(text.String(), "\n"))).
+		AddButtons([]string{action, "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != action {
+				logger.Log("showKeybindingConflicts: %s cancelled", action)
+				back()
+				return
+			}
+			av.saveKeybinding(app, label, keybinding.String(), conflicts)
+		})
+	modal.SetTitle(title).SetBorder(true)
+
+	av.app.SetRoot(modal, true)
+	av.app.SetFocus(modal)
+}
+
+// saveKeybinding writes the keybinding labelled label, taking it away from conflicts,
+// and refreshes the list
+func (av *AppsView) saveKeybinding(app *config.Application, label, keybinding string, conflicts []hypr.Conflict) {
+	if err := hypr.ReassignKeybinding(app, label, keybinding, conflicts); err != nil {
+		logger.Log("Failed to add keybinding: %v", err)
+		av.showErrorModal(fmt.Sprintf("Failed to save keybinding: %v", err))
+		return
+	}
+
+	logger.Log("Keybinding saved: %s (%s) -> %s", app.Name, label, keybinding)
+	av.keyCapture = nil
+	av.reloadKeybindings()
+
+	// Return to main view
+	av.app.SetRoot(av.root, true)
+	av.app.SetFocus(av.list)
+	av.reloadHyprland()
+}
+
+// resetKeybinding removes or restores the app's keybinding labelled label with reset
+// (hypr.RemoveKeybinding or hypr.RestoreKeybinding) and refreshes the list
+func (av *AppsView) resetKeybinding(app *config.Application, label string, reset func(app *config.Application, label string) error) {
+	if err := reset(app, label); err != nil {
+		logger.Log("Failed to reset keybinding: %v", err)
+		av.showErrorModal(fmt.Sprintf("Failed to update keybinding: %v", err))
+		return
+	}
+	logger.Log("Keybinding reset: %s (%s)", app.Name, label)
+	av.reloadKeybindings()
+	av.reloadHyprland()
+}
+
+// reloadHyprland tells the running Hyprland to pick up bindings.conf changes and shows
+// the config errors it reports, so a broken binding is noticed right away
+func (av *AppsView) reloadHyprland() {
+	errs, err := hypr.ReloadConfig()
+	if errors.Is(err, hypr.ErrNotRunning) {
+		logger.Log("reloadHyprland: %v, skipping reload", err)
+		return
+	}
+	if err != nil {
+		logger.Log("reloadHyprland: Failed to reload Hyprland: %v", err)
+		av.showErrorModal(fmt.Sprintf("Saved, but reloading Hyprland failed: %v", err))
+		return
+	}
+	if len(errs) > 0 {
+		av.showErrorModal("Hyprland reported config errors:\n\n" + strings.Join(errs, "\n"))
+	}
+}
+
+// reloadKeybindings reloads the config from disk to pick up keybinding changes,
+// keeping the current selection
+func (av *AppsView) reloadKeybindings() {
+	// Store current selection index before reload
+	currentIndex := av.list.GetCurrentItem()
+
+	// Reload config from disk
+	if err := av.controller.ReloadConfig(); err != nil {
+		logger.Log("Failed to reload config: %v", err)
+	}
+
+	// Refresh apps list to show updated keybinding (filtered by current category)
+	av.LoadApps(av.controller.GetFilteredApps())
+
+	// Restore selection if still valid
+	if currentIndex >= 0 && currentIndex < len(av.apps) {
+		av.list.SetCurrentItem(currentIndex)
+		// Update controller selection
+		av.controller.SetSelectedAppSilent(&av.apps[currentIndex])
+	}
+}
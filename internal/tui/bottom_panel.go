@@ -77,12 +77,47 @@ func (bp *BottomPanel) UpdateAppInfo(app *config.Application, isDefault bool) {
 	text += fmt.Sprintf("[yellow]Package:[-] %s\n", app.PackageName)
 	text += fmt.Sprintf("[yellow]Category:[-] %s\n", app.Category)
 
+	if !app.Placement.IsZero() {
+		text += fmt.Sprintf("[yellow]Placement:[-] %s\n", tview.Escape(app.Placement.String()))
+	}
+	if app.SingleInstance {
+		match := "class " + app.WindowClass()
+		if app.WindowTitle != "" {
+			match += " or title " + app.WindowTitle
+		}
+		text += fmt.Sprintf("[yellow]Single instance:[-] focuses an open window (%s)\n", tview.Escape(match))
+	}
+
 	if isDefault {
 		text += "[green]Status: Default app for category[-]\n"
 	} else {
 		text += "[yellow]Status: Not default[-]\n"
 	}
 
+	if len(app.Keybindings) > 0 {
+		text += "[yellow]Keybindings:[-]\n"
+		for _, binding := range app.Keybindings {
+			text += fmt.Sprintf("  %s: %s", tview.Escape(app.KeybindingLabel(binding)), binding.Keys)
+			if binding.Command != "" {
+				text += fmt.Sprintf(" -> %s", tview.Escape(binding.Command))
+			}
+			text += "\n"
+			if binding.Match != nil {
+				// Shown so wrong guesses can be spotted and fixed
+				text += fmt.Sprintf("    [gray]matched by %s[-]\n", tview.Escape(binding.Match.String()))
+			}
+		}
+	} else {
+		text += "[yellow]Keybindings:[-] none\n"
+	}
+
+	if rules := bp.controller.GetWindowRules(app); len(rules) > 0 {
+		text += fmt.Sprintf("[yellow]Window rules (class %s):[-]\n", tview.Escape(app.WindowClass()))
+		for _, rule := range rules {
+			text += fmt.Sprintf("  %s [gray](%s)[-]\n", tview.Escape(rule.Rule), tview.Escape(rule.Location()))
+		}
+	}
+
 	if app.ConfigFile != "" {
 		text += fmt.Sprintf("\n[yellow]Config File:[-] %s\n", app.ConfigFile)
 	}
@@ -94,6 +129,16 @@ func (bp *BottomPanel) UpdateAppInfo(app *config.Application, isDefault bool) {
 		}
 	}
 
+	if launchErr := bp.controller.GetLaunchError(app); launchErr != nil {
+		text += fmt.Sprintf("\n[red]Last launch failed:[-] %s\n", tview.Escape(launchErr.Err.Error()))
+		if launchErr.LogPath != "" {
+			text += fmt.Sprintf("[yellow]Log:[-] %s\n", launchErr.LogPath)
+		}
+		for _, line := range launchErr.LogTail {
+			text += fmt.Sprintf("  %s\n", tview.Escape(line))
+		}
+	}
+
 	bp.textView.Clear()
 	fmt.Fprint(bp.textView, text)
 }
@@ -108,12 +153,7 @@ func (bp *BottomPanel) updateInfo() {
 	selectedApp := bp.controller.GetSelectedApp()
 
 	if selectedApp != nil {
-		isDefault := false
-		defaultApp := bp.controller.GetDefaultAppForCategory(selectedApp.Category)
-		if defaultApp != nil && selectedApp.PackageName == defaultApp.PackageName {
-			isDefault = true
-		}
-		bp.UpdateAppInfo(selectedApp, isDefault)
+		bp.UpdateAppInfo(selectedApp, bp.controller.IsDefaultApp(selectedApp))
 	} else {
 		// Show empty state
 		bp.textView.Clear()
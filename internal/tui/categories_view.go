@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"omarchy-tui/internal/config"
 	"omarchy-tui/internal/logger"
 
@@ -13,16 +14,22 @@ type CategoriesView struct {
 	controller       *Controller
 	categories       []config.Category // includes synthetic "All" category at index 0
 	onCategoryChange func(categoryID string)
+	onDefaultChange  func() // called after a category default app is chosen
+	app              *tview.Application
+	root             tview.Primitive
 }
 
 // NewCategoriesView creates a new categories view
 // Note: onCategoryChange callback is NOT triggered during initial load
-func NewCategoriesView(controller *Controller, onCategoryChange func(categoryID string)) *CategoriesView {
+func NewCategoriesView(controller *Controller, app *tview.Application, root tview.Primitive, onCategoryChange func(categoryID string)) *CategoriesView {
 	cv := &CategoriesView{
 		list:             tview.NewList(),
 		controller:       controller,
 		categories:       []config.Category{},
 		onCategoryChange: nil, // Set to nil initially to avoid triggering during load
+		onDefaultChange:  func() {},
+		app:              app,
+		root:             root,
 	}
 
 	cv.list.SetBorder(true)
@@ -33,6 +40,14 @@ func NewCategoriesView(controller *Controller, onCategoryChange func(categoryID
 		cv.updateSelection()
 	})
 
+	// Enter on a category opens the default app picker
+	cv.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index > 0 && index < len(cv.categories) {
+			logger.Log("CategoriesView: Enter pressed, choosing default for: %s", cv.categories[index].ID)
+			cv.showDefaultAppPicker(&cv.categories[index])
+		}
+	})
+
 	// Load categories (won't trigger callback since onCategoryChange is nil)
 	cv.loadCategories()
 
@@ -102,3 +117,75 @@ func (cv *CategoriesView) GetSelectedCategory() *config.Category {
 func (cv *CategoriesView) Reload() {
 	cv.loadCategories()
 }
+
+// showDefaultAppPicker displays a list of the category's apps to choose its default from
+func (cv *CategoriesView) showDefaultAppPicker(category *config.Category) {
+	apps := cv.controller.GetConfig().GetAppsByCategory(category.ID)
+	if len(apps) == 0 {
+		logger.Log("showDefaultAppPicker: No apps in category %s", category.ID)
+		return
+	}
+
+	closePicker := func() {
+		cv.app.SetRoot(cv.root, true)
+		cv.app.SetFocus(cv.list)
+	}
+
+	picker := tview.NewList().ShowSecondaryText(false)
+	currentIndex := 0
+	for i := range apps {
+		mainText := apps[i].Name
+		if cv.controller.IsDefaultApp(&apps[i]) {
+			mainText = "* " + mainText
+			currentIndex = i
+		}
+		picker.AddItem(mainText, "", 0, nil)
+	}
+	picker.SetCurrentItem(currentIndex)
+
+	picker.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		closePicker()
+		app := apps[index]
+		err := cv.controller.SetDefaultApp(category.ID, &app)
+		// Refresh even on failure: part of the change may already be saved
+		cv.onDefaultChange()
+		if err != nil {
+			logger.Log("showDefaultAppPicker: Failed to set default: %v", err)
+			cv.showErrorModal(fmt.Sprintf("Failed to set default app: %v", err))
+		}
+	})
+	picker.SetDoneFunc(closePicker)
+
+	picker.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Default app for %s ", category.Name)).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(picker, 50, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			0, 2, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	cv.app.SetRoot(dialog, true)
+	cv.app.SetFocus(picker)
+}
+
+// showErrorModal displays an error message until dismissed
+func (cv *CategoriesView) showErrorModal(message string) {
+	modal := tview.NewModal().
+		SetText(tview.Escape(message)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			cv.app.SetRoot(cv.root, true)
+			cv.app.SetFocus(cv.list)
+		})
+	modal.SetTitle(" Error ").SetBorder(true)
+
+	cv.app.SetRoot(modal, true)
+	cv.app.SetFocus(modal)
+}
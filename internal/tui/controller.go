@@ -1,9 +1,13 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"omarchy-tui/internal/config"
 	"omarchy-tui/internal/exec"
+	"omarchy-tui/internal/hypr"
 	"omarchy-tui/internal/logger"
+	"strings"
 )
 
 // EditMode represents the current editing mode
@@ -15,21 +19,24 @@ const (
 	EditModeAppConfig
 )
 
+// terminalCategoryID is the category whose default app is used as terminal emulator
+const terminalCategoryID = "terminal"
+
 // Controller manages application state and coordinates between views
 type Controller struct {
 	config           *config.OmarchyConfig
 	selectedApp      *config.Application
-	selectedCategory string                         // "" means "All"
-	defaultApps      map[string]*config.Application // categoryID -> app
+	selectedCategory string // "" means "All"
 	editMode         EditMode
-	onStateChange    func() // callback for view updates
+	lastLaunchError  *exec.LaunchError // most recent failed launch, shown in the bottom panel
+	windowRules      []hypr.WindowRule // every window rule in the Hyprland config, nil until loaded
+	onStateChange    func()            // callback for view updates
 }
 
 // NewController creates a new controller instance
 func NewController(cfg *config.OmarchyConfig) *Controller {
 	return &Controller{
 		config:        cfg,
-		defaultApps:   make(map[string]*config.Application),
 		editMode:      EditModeNone,
 		onStateChange: func() {},
 	}
@@ -92,29 +99,124 @@ func (c *Controller) GetCategories() []config.Category {
 	return c.config.Categories
 }
 
-// SetDefaultApp sets the default app for a category
+// SetDefaultApp sets the default app for a category, saves it to the config file
+// and propagates it to mimeapps.list and the mapped Hyprland variable
 func (c *Controller) SetDefaultApp(categoryID string, app *config.Application) error {
 	if app == nil {
 		return nil
 	}
 	logger.Log("Controller: Setting default app for category %s: %s", categoryID, app.Name)
-	c.defaultApps[categoryID] = app
+
+	// Save first so the UI never shows a default that wasn't persisted
+	err := config.UpdateConfig(func(cfg *config.OmarchyConfig) error {
+		cfg.SetDefaultApp(categoryID, app)
+		return nil
+	})
+	if err != nil {
+		logger.Log("Controller: Failed to save default app: %v", err)
+		return err
+	}
+	c.config.SetDefaultApp(categoryID, app)
 	c.notifyStateChange()
+
+	if err := config.SyncMimeDefaults(c.config, categoryID); err != nil {
+		logger.Log("Controller: Failed to update mimeapps.list: %v", err)
+		return fmt.Errorf("default saved, but updating mimeapps.list failed: %w", err)
+	}
+
+	if variable := c.config.HyprVariables[categoryID]; variable != "" {
+		// The command the launcher runs: a Terminal=true editor like nvim does nothing
+		// from a keybind unless it is wrapped in the terminal
+		argv, err := exec.ResolveCommand(app, exec.LaunchOptions{Terminal: c.GetDefaultTerminal()})
+		if err != nil {
+			return fmt.Errorf("default saved, but building command for $%s failed: %w", variable, err)
+		}
+		if err := hypr.SetVariable(variable, exec.JoinCommand(argv)); err != nil {
+			logger.Log("Controller: Failed to update Hyprland variable $%s: %v", variable, err)
+			return fmt.Errorf("default saved, but updating $%s failed: %w", variable, err)
+		}
+		errs, err := hypr.ReloadConfig()
+		if errors.Is(err, hypr.ErrNotRunning) {
+			logger.Log("Controller: %v, skipping reload", err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("default saved, but reloading Hyprland failed: %w", err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("default saved, but Hyprland reported config errors:\n\n%s", strings.Join(errs, "\n"))
+		}
+	}
 	return nil
 }
 
 // GetDefaultAppForCategory returns the default app for a category, or nil if none set
 func (c *Controller) GetDefaultAppForCategory(categoryID string) *config.Application {
-	return c.defaultApps[categoryID]
+	return c.config.GetDefaultApp(categoryID)
+}
+
+// IsDefaultApp reports whether app is the default app of its category
+func (c *Controller) IsDefaultApp(app *config.Application) bool {
+	defaultApp := c.GetDefaultAppForCategory(app.Category)
+	return defaultApp != nil && defaultApp.Name == app.Name && defaultApp.PackageName == app.PackageName
+}
+
+// GetDefaultTerminal returns the terminal emulator used for Terminal=true apps
+// It prefers the default of the "terminal" category, then any category default
+// that is a known terminal emulator (e.g. the "system" category of generated configs)
+func (c *Controller) GetDefaultTerminal() *config.Application {
+	if app := c.config.GetDefaultApp(terminalCategoryID); app != nil {
+		return app
+	}
+	for categoryID := range c.config.Defaults {
+		app := c.config.GetDefaultApp(categoryID)
+		if app == nil {
+			continue
+		}
+		if argv, err := exec.BuildCommand(app); err == nil && exec.IsKnownTerminal(argv) {
+			return app
+		}
+	}
+	return nil
 }
 
 // LaunchApp launches the given application
-func (c *Controller) LaunchApp(app *config.Application) error {
+// onEarlyExit is called from a background goroutine if the app fails right after
+// starting; the caller hands the error back to the event loop (see RecordLaunchError).
+func (c *Controller) LaunchApp(app *config.Application, onEarlyExit func(err error)) error {
 	if app == nil {
 		return nil
 	}
-	logger.Log("Controller: Launching app: %s (package: %s)", app.Name, app.PackageName)
-	return exec.LaunchApp(app.PackageName)
+	logger.Log("Controller: Launching app: %s (exec: %s)", app.Name, app.Exec)
+	err := exec.LaunchApp(app, exec.LaunchOptions{
+		Terminal: c.GetDefaultTerminal(),
+		OnEarlyExit: func(launchErr *exec.LaunchError) {
+			onEarlyExit(launchErr)
+		},
+	})
+	c.RecordLaunchError(err)
+	return err
+}
+
+// RecordLaunchError remembers the outcome of the last launch for the bottom panel
+// Must be called from the event loop.
+func (c *Controller) RecordLaunchError(err error) {
+	var launchErr *exec.LaunchError
+	if errors.As(err, &launchErr) {
+		logger.Log("Controller: Launch failed: %v (log: %s)", launchErr, launchErr.LogPath)
+		c.lastLaunchError = launchErr
+	} else {
+		c.lastLaunchError = nil
+	}
+	c.notifyStateChange()
+}
+
+// GetLaunchError returns the last launch failure for app, or nil if its last launch succeeded
+func (c *Controller) GetLaunchError(app *config.Application) *exec.LaunchError {
+	if app == nil || c.lastLaunchError == nil || c.lastLaunchError.App != app.Name {
+		return nil
+	}
+	return c.lastLaunchError
 }
 
 // EnterEditMode switches to the specified edit mode
@@ -131,6 +233,28 @@ func (c *Controller) CancelEdit() {
 	c.notifyStateChange()
 }
 
+// GetWindowRules returns the Hyprland window rules that apply to app's windows
+// The config is parsed once and cached until InvalidateWindowRules.
+func (c *Controller) GetWindowRules(app *config.Application) []hypr.WindowRule {
+	if app == nil {
+		return nil
+	}
+	if c.windowRules == nil {
+		rules, err := hypr.LoadWindowRules()
+		if err != nil {
+			logger.Log("Controller: Failed to load window rules: %v", err)
+		}
+		c.windowRules = append([]hypr.WindowRule{}, rules...)
+	}
+	return hypr.WindowRulesFor(c.windowRules, app.WindowClass())
+}
+
+// InvalidateWindowRules drops the cached window rules after they were edited
+func (c *Controller) InvalidateWindowRules() {
+	c.windowRules = nil
+	c.notifyStateChange()
+}
+
 // GetEditMode returns the current edit mode
 func (c *Controller) GetEditMode() EditMode {
 	return c.editMode
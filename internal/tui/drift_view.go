@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"omarchy-tui/internal/hypr"
+	"omarchy-tui/internal/logger"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// DriftView shows where the binds active in Hyprland differ from the config files
+// (binds added by plugins or `hyprctl keyword`, files that failed to load, ...)
+type DriftView struct {
+	text    *tview.TextView
+	app     *tview.Application
+	root    tview.Primitive
+	onClose func()
+}
+
+// NewDriftView creates a drift view; onClose restores focus after it is dismissed
+func NewDriftView(app *tview.Application, root tview.Primitive, onClose func()) *DriftView {
+	dv := &DriftView{
+		text:    tview.NewTextView(),
+		app:     app,
+		root:    root,
+		onClose: onClose,
+	}
+
+	dv.text.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true)
+	dv.text.SetBorder(true).
+		SetTitle(" Live binds vs config files (Esc to close) ").
+		SetTitleAlign(tview.AlignCenter)
+	dv.text.SetDoneFunc(func(key tcell.Key) {
+		dv.app.SetRoot(dv.root, true)
+		if dv.onClose != nil {
+			dv.onClose()
+		}
+	})
+
+	return dv
+}
+
+// Show queries Hyprland and displays the differences
+func (dv *DriftView) Show() {
+	drifts, err := hypr.FindDrift()
+	dv.text.Clear()
+	switch {
+	case errors.Is(err, hypr.ErrNotRunning):
+		fmt.Fprint(dv.text, "[yellow]Hyprland is not running, there are no live binds to compare.[-]")
+	case err != nil:
+		logger.Log("DriftView: Failed to compare binds: %v", err)
+		fmt.Fprintf(dv.text, "[red]Failed to compare binds:[-] %s", tview.Escape(err.Error()))
+	case len(drifts) == 0:
+		fmt.Fprint(dv.text, "[green]The live binds match the config files.[-]")
+	default:
+		logger.Log("DriftView: Found %d differences", len(drifts))
+		fmt.Fprint(dv.text, formatDrifts(drifts))
+	}
+
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(dv.text, 0, 8, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			0, 8, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	dv.app.SetRoot(dialog, true)
+	dv.app.SetFocus(dv.text)
+}
+
+// formatDrifts renders the differences grouped by kind
+func formatDrifts(drifts []hypr.Drift) string {
+	var text strings.Builder
+	for _, kind := range []hypr.DriftKind{hypr.DriftNotInFiles, hypr.DriftNotLoaded, hypr.DriftDispatcherChanged} {
+		var lines []string
+		for _, drift := range drifts {
+			if drift.Kind != kind {
+				continue
+			}
+			line := fmt.Sprintf("  [white]%s[-]", tview.Escape(drift.Keys()))
+			if live := drift.LiveAction(); live != "" {
+				line += fmt.Sprintf("\n    live: %s", tview.Escape(live))
+			}
+			if file := drift.FileAction(); file != "" {
+				line += fmt.Sprintf("\n    file: %s", tview.Escape(file))
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&text, "[yellow]%s (%d)[-]\n%s\n\n", kind, len(lines), strings.Join(lines, "\n"))
+	}
+	return strings.TrimRight(text.String(), "\n")
+}
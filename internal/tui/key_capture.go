@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// modifierToggles are the fallback toggles for modifiers the terminal can't report
+// (SUPER never reaches a terminal application; others may be eaten by the terminal).
+// They only act outside capture mode, where the keys themselves can be recorded.
+var modifierToggles = []struct {
+	key  tcell.Key
+	mod  config.Modifier
+	name string
+}{
+	{tcell.KeyF5, config.ModSuper, "SUPER"},
+	{tcell.KeyF6, config.ModCtrl, "CTRL"},
+	{tcell.KeyF7, config.ModAlt, "ALT"},
+	{tcell.KeyF8, config.ModShift, "SHIFT"},
+}
+
+// captureKeyNames maps special tcell keys to their Hyprland (XKB) names
+var captureKeyNames = map[tcell.Key]string{
+	tcell.KeyEnter:      "Return",
+	tcell.KeyTab:        "Tab",
+	tcell.KeyBacktab:    "Tab",
+	tcell.KeyBackspace:  "BackSpace",
+	tcell.KeyBackspace2: "BackSpace",
+	tcell.KeyDelete:     "Delete",
+	tcell.KeyInsert:     "Insert",
+	tcell.KeyHome:       "Home",
+	tcell.KeyEnd:        "End",
+	tcell.KeyPgUp:       "Page_Up",
+	tcell.KeyPgDn:       "Page_Down",
+	tcell.KeyUp:         "Up",
+	tcell.KeyDown:       "Down",
+	tcell.KeyLeft:       "Left",
+	tcell.KeyRight:      "Right",
+	tcell.KeyPrint:      "Print",
+	tcell.KeyPause:      "Pause",
+}
+
+// captureRuneNames maps punctuation to XKB keysym names
+var captureRuneNames = map[rune]string{
+	' ':  "Space",
+	',':  "comma",
+	'.':  "period",
+	'/':  "slash",
+	'\\': "backslash",
+	'-':  "minus",
+	'=':  "equal",
+	';':  "semicolon",
+	'\'': "apostrophe",
+	'`':  "grave",
+	'[':  "bracketleft",
+	']':  "bracketright",
+}
+
+// keyCapture is the state of an open keybinding dialog
+// The central router in app.go hands it every key event while the dialog has focus.
+type keyCapture struct {
+	input        *tview.InputField
+	instructions *tview.TextView
+	toggles      *tview.TextView
+	mods         config.Modifier // modifiers of the typed keybinding when capture started, added to the captured chord
+	capturing    bool
+}
+
+// newKeyCapture creates the capture state for a keybinding dialog around input
+func newKeyCapture(input *tview.InputField) *keyCapture {
+	kc := &keyCapture{
+		input:        input,
+		instructions: tview.NewTextView().SetTextAlign(tview.AlignCenter),
+		toggles:      tview.NewTextView().SetTextAlign(tview.AlignCenter).SetDynamicColors(true),
+	}
+	input.SetChangedFunc(func(text string) {
+		kc.render()
+	})
+	kc.render()
+	return kc
+}
+
+// handle processes a key event for the dialog, returning true if it was consumed
+// F2 and the modifier toggles are dialog keys only outside capture mode; while
+// capturing they are recorded like any other key.
+func (kc *keyCapture) handle(event *tcell.EventKey) bool {
+	if !kc.capturing {
+		for _, toggle := range modifierToggles {
+			if event.Key() == toggle.key {
+				kc.toggle(toggle.mod)
+				return true
+			}
+		}
+		if event.Key() == tcell.KeyF2 {
+			// Modifiers toggled on the typed keybinding carry over, so SUPER can
+			// be combined with a captured key
+			keybinding, _ := config.ParseKeybinding(kc.input.GetText())
+			kc.mods = keybinding.Mods
+			kc.capturing = true
+			kc.render()
+			return true
+		}
+		return false
+	}
+
+	// While capturing, Esc stops capturing instead of closing the dialog
+	if event.Key() == tcell.KeyEscape {
+		kc.capturing = false
+		kc.mods = 0
+		kc.render()
+		return true
+	}
+
+	keybinding, ok := keybindingFromEvent(event)
+	if !ok {
+		return true
+	}
+	keybinding.Mods |= kc.mods
+	kc.capturing = false
+	kc.mods = 0
+	kc.input.SetText(keybinding.String())
+	return true
+}
+
+// toggle flips a modifier on the typed keybinding
+func (kc *keyCapture) toggle(mod config.Modifier) {
+	keybinding, err := config.ParseKeybinding(kc.input.GetText())
+	if err != nil || !keybinding.IsValid() {
+		return
+	}
+	keybinding.Mods ^= mod
+	kc.input.SetText(keybinding.Canonical().String())
+}
+
+// render updates the instructions and the modifier toggle row
+func (kc *keyCapture) render() {
+	if kc.capturing {
+		kc.instructions.SetText("Press the key combination to record (F-keys included), Esc to stop capturing")
+	} else {
+		kc.instructions.SetText("Enter to save, Esc to cancel, F2 to capture keys")
+	}
+
+	active := kc.mods
+	if !kc.capturing {
+		keybinding, _ := config.ParseKeybinding(kc.input.GetText())
+		active = keybinding.Mods
+	}
+
+	var row []string
+	for _, toggle := range modifierToggles {
+		label := fmt.Sprintf("%s %s", tcell.KeyNames[toggle.key], toggle.name)
+		if active&toggle.mod != 0 {
+			label = "[::r] " + label + " [::-]"
+		} else {
+			label = " " + label + " "
+		}
+		row = append(row, label)
+	}
+	kc.toggles.SetText(strings.Join(row, "  "))
+}
+
+// keybindingFromEvent converts a key event into a keybinding
+// Returns false for events that don't name a key (e.g. a bare Esc while capturing).
+func keybindingFromEvent(event *tcell.EventKey) (config.Keybinding, bool) {
+	var keybinding config.Keybinding
+	mods := event.Modifiers()
+	if mods&tcell.ModShift != 0 {
+		keybinding.Mods |= config.ModShift
+	}
+	if mods&tcell.ModCtrl != 0 {
+		keybinding.Mods |= config.ModCtrl
+	}
+	if mods&tcell.ModAlt != 0 {
+		keybinding.Mods |= config.ModAlt
+	}
+	if mods&tcell.ModMeta != 0 {
+		// Terminals that report the xterm meta bit send it for the logo key
+		keybinding.Mods |= config.ModSuper
+	}
+
+	key := event.Key()
+	switch {
+	case key == tcell.KeyRune:
+		r := event.Rune()
+		if name, ok := captureRuneNames[r]; ok {
+			keybinding.Key = name
+		} else {
+			if unicode.IsUpper(r) {
+				keybinding.Mods |= config.ModShift
+			}
+			keybinding.Key = strings.ToUpper(string(r))
+		}
+	case key >= tcell.KeyF1 && key <= tcell.KeyF64:
+		keybinding.Key = fmt.Sprintf("F%d", int(key-tcell.KeyF1)+1)
+	case captureKeyNames[key] != "" && (mods&tcell.ModCtrl == 0 || key > tcell.KeyDEL):
+		keybinding.Key = captureKeyNames[key]
+		if key == tcell.KeyBacktab {
+			keybinding.Mods |= config.ModShift
+		}
+	case key >= tcell.KeyCtrlA && key <= tcell.KeyCtrlZ:
+		// Legacy terminals encode Ctrl+letter as a control character
+		keybinding.Mods |= config.ModCtrl
+		keybinding.Key = string(rune('A' + key - tcell.KeyCtrlA))
+	default:
+		return config.Keybinding{}, false
+	}
+	return keybinding, true
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"omarchy-tui/internal/config"
+	"omarchy-tui/internal/hypr"
+	"omarchy-tui/internal/logger"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// windowRuleExamples hints at common window rule effects in the rule dialog
+const windowRuleExamples = "e.g. float, tile, size 1200 800, opacity 0.9 0.8, workspace 2"
+
+// showWindowRules lists the window rules matching app's class, with an entry to add one
+func (av *AppsView) showWindowRules(app *config.Application) {
+	rules := av.controller.GetWindowRules(app)
+
+	closeList := func() {
+		av.app.SetRoot(av.root, true)
+		av.app.SetFocus(av.list)
+	}
+
+	list := tview.NewList()
+	for _, rule := range rules {
+		secondary := rule.Location()
+		if !rule.Managed() {
+			secondary += " (defined in another file, can be overridden)"
+		}
+		list.AddItem(rule.Rule, secondary, 0, nil)
+	}
+	list.AddItem("+ Add rule", "class "+app.WindowClass(), 0, nil)
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index >= len(rules) {
+			av.showWindowRuleInput(app, nil)
+			return
+		}
+		av.showWindowRuleActions(app, rules[index])
+	})
+	list.SetDoneFunc(closeList)
+
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Window rules for %s ", app.Name)).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(list, 80, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			0, 2, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	av.app.SetRoot(dialog, true)
+	av.app.SetFocus(list)
+}
+
+// showWindowRuleActions offers editing or removing a rule
+// Rules outside the managed file can only be overridden by adding a rule.
+func (av *AppsView) showWindowRuleActions(app *config.Application, rule hypr.WindowRule) {
+	text := fmt.Sprintf("%s\n\n%s", tview.Escape(rule.String()), rule.Location())
+	buttons := []string{"Edit", "Remove", "Cancel"}
+	if !rule.Managed() {
+		text += "\n\nThis rule lives in another file and can't be edited here. Override adds a rule for this app that Hyprland applies after it."
+		buttons = []string{"Override", "Cancel"}
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Edit", "Override":
+				av.showWindowRuleInput(app, &rule)
+			case "Remove":
+				av.saveWindowRule(app, func() error { return hypr.RemoveWindowRule(rule) })
+			default:
+				av.showWindowRules(app)
+			}
+		})
+
+	av.app.SetRoot(modal, true)
+	av.app.SetFocus(modal)
+}
+
+// showWindowRuleInput asks for a rule's effect; rule is nil when adding a new one
+// A rule outside the managed file is overridden: its effect is the starting text of
+// a new rule for the app.
+func (av *AppsView) showWindowRuleInput(app *config.Application, rule *hypr.WindowRule) {
+	inputField := tview.NewInputField().
+		SetLabel("Rule: ").
+		SetPlaceholder(windowRuleExamples).
+		SetFieldWidth(60)
+	title := fmt.Sprintf(" Add window rule for class %s ", app.WindowClass())
+	if rule != nil {
+		inputField.SetText(rule.Rule)
+		title = " Edit window rule "
+		if !rule.Managed() {
+			title = fmt.Sprintf(" Override %s for class %s ", rule.Location(), app.WindowClass())
+		}
+	}
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			text := strings.TrimSpace(inputField.GetText())
+			if text == "" || strings.ContainsAny(text, ",\n") {
+				av.showErrorModal("A window rule is a single effect without commas, " + windowRuleExamples)
+				return
+			}
+			if rule == nil || !rule.Managed() {
+				av.saveWindowRule(app, func() error { return hypr.AddWindowRule(app.WindowClass(), text) })
+			} else {
+				av.saveWindowRule(app, func() error { return hypr.UpdateWindowRule(*rule, text) })
+			}
+		case tcell.KeyEscape:
+			av.showWindowRules(app)
+		}
+	})
+
+	inputField.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Create centered container
+	dialog := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewBox(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewBox(), 0, 1, false).
+			AddItem(inputField, 80, 0, true).
+			AddItem(tview.NewBox(), 0, 1, false),
+			3, 0, true).
+		AddItem(tview.NewBox(), 0, 1, false)
+
+	av.app.SetRoot(dialog, true)
+	av.app.SetFocus(inputField)
+}
+
+// saveWindowRule runs a window rule change, then reloads Hyprland and reopens the rule list
+func (av *AppsView) saveWindowRule(app *config.Application, change func() error) {
+	if err := change(); err != nil {
+		logger.Log("saveWindowRule: Failed to update window rules for %s: %v", app.Name, err)
+		av.showErrorModal(fmt.Sprintf("Failed to update window rules: %v", err))
+		return
+	}
+	logger.Log("saveWindowRule: Updated window rules for %s", app.Name)
+
+	av.controller.InvalidateWindowRules()
+	av.showWindowRules(app)
+	av.reloadHyprland()
+}